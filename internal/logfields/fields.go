@@ -7,15 +7,129 @@ SPDX-License-Identifier: Apache-2.0
 package logfields
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 )
 
 const (
 	// FieldCertPoolSize log field name.
 	FieldCertPoolSize = "certPoolSize"
+	// FieldConfigKey log field name.
+	FieldConfigKey = "configKey"
+	// FieldSuggestion log field name.
+	FieldSuggestion = "suggestion"
+	// FieldParameter log field name.
+	FieldParameter = "parameter"
+	// FieldEnvKey log field name.
+	FieldEnvKey = "envKey"
+	// FieldSource log field name.
+	FieldSource = "source"
+	// FieldValue log field name.
+	FieldValue = "value"
+	// FieldSystemCertPool log field name.
+	FieldSystemCertPool = "systemCertPool"
+	// FieldCACertCount log field name.
+	FieldCACertCount = "caCertCount"
+	// FieldCertPath log field name.
+	FieldCertPath = "certPath"
+	// FieldCertSubject log field name.
+	FieldCertSubject = "certSubject"
+	// FieldCertExpiry log field name.
+	FieldCertExpiry = "certExpiry"
+	// FieldFeature log field name.
+	FieldFeature = "feature"
+	// FieldStage log field name.
+	FieldStage = "stage"
+	// FieldEnabled log field name.
+	FieldEnabled = "enabled"
+	// FieldAllowedSources log field name.
+	FieldAllowedSources = "allowedSources"
+	// FieldFingerprint log field name.
+	FieldFingerprint = "fingerprint"
 )
 
 // WithCertPoolSize sets the CertPoolSize field.
 func WithCertPoolSize(value int) zap.Field {
 	return zap.Int(FieldCertPoolSize, value)
 }
+
+// WithConfigKey sets the ConfigKey field.
+func WithConfigKey(value string) zap.Field {
+	return zap.String(FieldConfigKey, value)
+}
+
+// WithSuggestion sets the Suggestion field.
+func WithSuggestion(value string) zap.Field {
+	return zap.String(FieldSuggestion, value)
+}
+
+// WithParameter sets the Parameter field.
+func WithParameter(value string) zap.Field {
+	return zap.String(FieldParameter, value)
+}
+
+// WithEnvKey sets the EnvKey field.
+func WithEnvKey(value string) zap.Field {
+	return zap.String(FieldEnvKey, value)
+}
+
+// WithSource sets the Source field.
+func WithSource(value string) zap.Field {
+	return zap.String(FieldSource, value)
+}
+
+// WithValue sets the Value field.
+func WithValue(value string) zap.Field {
+	return zap.String(FieldValue, value)
+}
+
+// WithSystemCertPool sets the SystemCertPool field.
+func WithSystemCertPool(value bool) zap.Field {
+	return zap.Bool(FieldSystemCertPool, value)
+}
+
+// WithCACertCount sets the CACertCount field.
+func WithCACertCount(value int) zap.Field {
+	return zap.Int(FieldCACertCount, value)
+}
+
+// WithCertPath sets the CertPath field.
+func WithCertPath(value string) zap.Field {
+	return zap.String(FieldCertPath, value)
+}
+
+// WithCertSubject sets the CertSubject field.
+func WithCertSubject(value string) zap.Field {
+	return zap.String(FieldCertSubject, value)
+}
+
+// WithCertExpiry sets the CertExpiry field.
+func WithCertExpiry(value time.Time) zap.Field {
+	return zap.Time(FieldCertExpiry, value)
+}
+
+// WithFeature sets the Feature field.
+func WithFeature(value string) zap.Field {
+	return zap.String(FieldFeature, value)
+}
+
+// WithStage sets the Stage field.
+func WithStage(value string) zap.Field {
+	return zap.String(FieldStage, value)
+}
+
+// WithEnabled sets the Enabled field.
+func WithEnabled(value bool) zap.Field {
+	return zap.Bool(FieldEnabled, value)
+}
+
+// WithAllowedSources sets the AllowedSources field.
+func WithAllowedSources(value []string) zap.Field {
+	return zap.Strings(FieldAllowedSources, value)
+}
+
+// WithFingerprint sets the fingerprint field.
+func WithFingerprint(value string) zap.Field {
+	return zap.String(FieldFingerprint, value)
+}
@@ -10,6 +10,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 	"github.com/trustbloc/logutil-go/pkg/log"
@@ -27,15 +28,63 @@ func TestStandardFields(t *testing.T) {
 		logger := log.New(module, log.WithStdOut(stdOut), log.WithEncoding(log.JSON))
 
 		certPoolSize := 10
+		configKey := "ORB_HOST_ULR"
+		suggestion := "ORB_HOST_URL"
+		parameter := "host-url"
+		envKey := "ORB_HOST_URL"
+		source := "env"
+		value := "localhost:8080"
+		systemCertPool := true
+		caCertCount := 3
+		certPath := "/etc/tls/cert.pem"
+		certSubject := "CN=orb.example.com"
+		certExpiry := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+		feature := "MyFeature"
+		stage := "beta"
+		enabled := true
+		allowedSources := []string{"file", "exec"}
+		fingerprint := "sha256:0123"
 
 		logger.Info(
 			"Some message",
 			WithCertPoolSize(certPoolSize),
+			WithConfigKey(configKey),
+			WithSuggestion(suggestion),
+			WithParameter(parameter),
+			WithEnvKey(envKey),
+			WithSource(source),
+			WithValue(value),
+			WithSystemCertPool(systemCertPool),
+			WithCACertCount(caCertCount),
+			WithCertPath(certPath),
+			WithCertSubject(certSubject),
+			WithCertExpiry(certExpiry),
+			WithFeature(feature),
+			WithStage(stage),
+			WithEnabled(enabled),
+			WithAllowedSources(allowedSources),
+			WithFingerprint(fingerprint),
 		)
 
 		l := unmarshalLogData(t, stdOut.Bytes())
 
 		require.Equal(t, certPoolSize, l.CertPoolSize)
+		require.Equal(t, configKey, l.ConfigKey)
+		require.Equal(t, suggestion, l.Suggestion)
+		require.Equal(t, parameter, l.Parameter)
+		require.Equal(t, envKey, l.EnvKey)
+		require.Equal(t, source, l.Source)
+		require.Equal(t, value, l.Value)
+		require.Equal(t, systemCertPool, l.SystemCertPool)
+		require.Equal(t, caCertCount, l.CACertCount)
+		require.Equal(t, certPath, l.CertPath)
+		require.Equal(t, certSubject, l.CertSubject)
+		require.True(t, certExpiry.Equal(l.CertExpiry))
+		require.Equal(t, feature, l.Feature)
+		require.Equal(t, stage, l.Stage)
+		require.Equal(t, enabled, l.Enabled)
+		require.Equal(t, allowedSources, l.AllowedSources)
+		require.Equal(t, fingerprint, l.Fingerprint)
 	})
 }
 
@@ -47,7 +96,23 @@ type logData struct {
 	Msg    string `json:"msg"`
 	Error  string `json:"error"`
 
-	CertPoolSize int `json:"certPoolSize"`
+	CertPoolSize   int       `json:"certPoolSize"`
+	ConfigKey      string    `json:"configKey"`
+	Suggestion     string    `json:"suggestion"`
+	Parameter      string    `json:"parameter"`
+	EnvKey         string    `json:"envKey"`
+	Source         string    `json:"source"`
+	Value          string    `json:"value"`
+	SystemCertPool bool      `json:"systemCertPool"`
+	CACertCount    int       `json:"caCertCount"`
+	CertPath       string    `json:"certPath"`
+	CertSubject    string    `json:"certSubject"`
+	CertExpiry     time.Time `json:"certExpiry"`
+	Feature        string    `json:"feature"`
+	Stage          string    `json:"stage"`
+	Enabled        bool      `json:"enabled"`
+	AllowedSources []string  `json:"allowedSources"`
+	Fingerprint    string    `json:"fingerprint"`
 }
 
 func unmarshalLogData(t *testing.T, b []byte) *logData {
@@ -0,0 +1,162 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// BytesEncoding is the encoding of a byte slice value.
+type BytesEncoding string
+
+// Supported byte slice encodings.
+const (
+	// Base64Encoding is standard or URL-safe base64, with or without padding.
+	Base64Encoding BytesEncoding = "base64"
+	// HexEncoding is hexadecimal.
+	HexEncoding BytesEncoding = "hex"
+)
+
+// BytesOptions specifies how a byte slice value is decoded and validated.
+type BytesOptions struct {
+	// Encoding is the encoding of a value which isn't prefixed with an encoding selector
+	// ("base64:" or "hex:"). Defaults to Base64Encoding.
+	Encoding BytesEncoding
+	// Length is the exact length in bytes of the decoded value. Zero means that any length is allowed.
+	Length int
+	// MinLength is the minimum length in bytes of the decoded value.
+	MinLength int
+}
+
+// SecretBytes is a byte slice holding sensitive data such as a key or a seed. It is redacted when
+// formatted, logged or marshalled.
+type SecretBytes []byte
+
+// String returns RedactedValue.
+func (s SecretBytes) String() string {
+	return RedactedValue
+}
+
+// GoString returns RedactedValue.
+func (s SecretBytes) GoString() string {
+	return RedactedValue
+}
+
+// Format writes RedactedValue for every verb so that the bytes are not formatted with %x or %v.
+func (s SecretBytes) Format(f fmt.State, _ rune) {
+	_, _ = f.Write([]byte(RedactedValue)) //nolint:errcheck // fmt.Formatter can't return an error
+}
+
+// MarshalText returns RedactedValue.
+func (s SecretBytes) MarshalText() ([]byte, error) {
+	return []byte(RedactedValue), nil
+}
+
+// GetBytes returns the byte slice set via either command line flag or environment variable.
+// If both are set, then the command line flag takes precedence.
+// The value may be prefixed with an encoding selector ("base64:" or "hex:"). Otherwise, it is decoded with
+// the encoding given in the options. The length of the decoded value is checked against the options.
+// If the variable isn't set and isOptional is true, then nil is returned.
+func GetBytes(cmd *cobra.Command, flagName, envKey string, opts BytesOptions, isOptional bool) ([]byte, error) {
+	value, err := GetUserSetVarFromString(cmd, flagName, envKey, isOptional)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == "" {
+		return nil, nil
+	}
+
+	b, err := DecodeBytes(value, opts.Encoding)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s (%s): %w", flagName, envKey, err)
+	}
+
+	if opts.Length > 0 && len(b) != opts.Length {
+		return nil, fmt.Errorf("invalid value for %s (%s): expecting %d bytes but got %d", flagName, envKey,
+			opts.Length, len(b))
+	}
+
+	if len(b) < opts.MinLength {
+		return nil, fmt.Errorf("invalid value for %s (%s): expecting at least %d bytes but got %d", flagName, envKey,
+			opts.MinLength, len(b))
+	}
+
+	return b, nil
+}
+
+// GetSecretBytes returns the byte slice set via either command line flag or environment variable as
+// SecretBytes. See GetBytes for details.
+func GetSecretBytes(cmd *cobra.Command, flagName, envKey string, opts BytesOptions,
+	isOptional bool) (SecretBytes, error) {
+	b, err := GetBytes(cmd, flagName, envKey, opts, isOptional)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// DecodeBytes decodes the given value. If the value is prefixed with an encoding selector ("base64:" or "hex:")
+// then the selected encoding is used. Otherwise, the value is decoded with the given encoding which defaults
+// to Base64Encoding.
+func DecodeBytes(value string, encoding BytesEncoding) ([]byte, error) {
+	for _, e := range []BytesEncoding{Base64Encoding, HexEncoding} {
+		if strings.HasPrefix(value, string(e)+":") {
+			encoding = e
+			value = strings.TrimPrefix(value, string(e)+":")
+
+			break
+		}
+	}
+
+	value = strings.TrimSpace(value)
+
+	switch encoding {
+	case "", Base64Encoding:
+		b, err := decodeBase64(value)
+		if err != nil {
+			return nil, fmt.Errorf("decode base64: %w", err)
+		}
+
+		return b, nil
+	case HexEncoding:
+		b, err := hex.DecodeString(strings.TrimPrefix(value, "0x"))
+		if err != nil {
+			return nil, fmt.Errorf("decode hex: %w", err)
+		}
+
+		return b, nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding [%s]", encoding)
+	}
+}
+
+// decodeBase64 decodes standard or URL-safe base64, with or without padding. A padded value must be padded
+// correctly and the unused bits of the last character must be zero.
+func decodeBase64(value string) ([]byte, error) {
+	urlSafe := strings.ContainsAny(value, "-_")
+
+	var enc *base64.Encoding
+
+	switch {
+	case strings.HasSuffix(value, "=") && urlSafe:
+		enc = base64.URLEncoding
+	case strings.HasSuffix(value, "="):
+		enc = base64.StdEncoding
+	case urlSafe:
+		enc = base64.RawURLEncoding
+	default:
+		enc = base64.RawStdEncoding
+	}
+
+	return enc.Strict().DecodeString(value)
+}
@@ -0,0 +1,149 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/logutil-go/pkg/log"
+	"go.uber.org/zap"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const (
+	seedFlagName = "seed"
+	seedEnvKey   = "TEST_SEED"
+)
+
+func TestDecodeBytes(t *testing.T) {
+	expected := []byte{0xfb, 0xff, 0xbf, 0x01}
+
+	for _, value := range []string{
+		"+/+/AQ==", "+/+/AQ", "-_-_AQ==", "-_-_AQ", "base64:+/+/AQ==", "base64:-_-_AQ", " +/+/AQ== ",
+		"hex:fbffbf01", "hex:0xFBFFBF01",
+	} {
+		b, err := cmd.DecodeBytes(value, "")
+		require.NoError(t, err, value)
+		require.Equal(t, expected, b, value)
+	}
+
+	b, err := cmd.DecodeBytes("fbffbf01", cmd.HexEncoding)
+	require.NoError(t, err)
+	require.Equal(t, expected, b)
+
+	b, err = cmd.DecodeBytes("base64:+/+/AQ==", cmd.HexEncoding)
+	require.NoError(t, err)
+	require.Equal(t, expected, b)
+
+	_, err = cmd.DecodeBytes("fbffbf0", cmd.HexEncoding)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decode hex")
+
+	_, err = cmd.DecodeBytes("!!!", "")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "decode base64")
+
+	for _, value := range []string{"+/+/AQ=", "+/+/AQ===", "+/+/A=Q=", "+/+/AR==", "+/+/AR", "-_-_AQ=", "+/+/A"} {
+		_, err = cmd.DecodeBytes(value, "")
+		require.Error(t, err, value)
+		require.Contains(t, err.Error(), "decode base64", value)
+	}
+
+	_, err = cmd.DecodeBytes("abc", "base32")
+	require.EqualError(t, err, "unsupported encoding [base32]")
+}
+
+func TestGetBytes(t *testing.T) {
+	t.Run("flag", func(t *testing.T) {
+		b, err := cmd.GetBytes(newCommand(t, seedFlags, "--seed", "hex:00010203"), seedFlagName, seedEnvKey,
+			cmd.BytesOptions{Length: 4}, false)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0, 1, 2, 3}, b)
+	})
+
+	t.Run("env var", func(t *testing.T) {
+		t.Setenv(seedEnvKey, "AAECAw")
+
+		b, err := cmd.GetBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{MinLength: 4}, false)
+		require.NoError(t, err)
+		require.Equal(t, []byte{0, 1, 2, 3}, b)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		b, err := cmd.GetBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{Length: 4}, true)
+		require.NoError(t, err)
+		require.Nil(t, b)
+
+		_, err = cmd.GetBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{}, false)
+		require.EqualError(t, err,
+			"Neither seed (command line flag) nor TEST_SEED (environment variable) have been set.")
+	})
+
+	t.Run("invalid length", func(t *testing.T) {
+		t.Setenv(seedEnvKey, "hex:000102")
+
+		_, err := cmd.GetBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{Length: 4}, false)
+		require.EqualError(t, err, "invalid value for seed (TEST_SEED): expecting 4 bytes but got 3")
+
+		_, err = cmd.GetBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{MinLength: 16}, false)
+		require.EqualError(t, err, "invalid value for seed (TEST_SEED): expecting at least 16 bytes but got 3")
+	})
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		t.Setenv(seedEnvKey, "xyz")
+
+		_, err := cmd.GetBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey,
+			cmd.BytesOptions{Encoding: cmd.HexEncoding}, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for seed (TEST_SEED): decode hex")
+		require.NotContains(t, err.Error(), "xyz")
+	})
+}
+
+func TestGetSecretBytes(t *testing.T) {
+	t.Setenv(seedEnvKey, "hex:00010203")
+
+	secret, err := cmd.GetSecretBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{}, false)
+	require.NoError(t, err)
+	require.Equal(t, cmd.SecretBytes{0, 1, 2, 3}, secret)
+
+	require.Equal(t, cmd.RedactedValue, secret.String())
+	require.Equal(t, cmd.RedactedValue, fmt.Sprintf("%v", secret))
+	require.Equal(t, cmd.RedactedValue, fmt.Sprintf("%x", secret))
+	require.Equal(t, cmd.RedactedValue, fmt.Sprintf("%#v", secret))
+
+	b, err := json.Marshal(struct{ Seed cmd.SecretBytes }{secret})
+	require.NoError(t, err)
+	require.Equal(t, `{"Seed":"[REDACTED]"}`, string(b))
+
+	out := &bytes.Buffer{}
+
+	log.New("test", log.WithStdOut(&syncWriter{out}), log.WithEncoding(log.JSON)).Info("Seed",
+		zap.Any("seed", secret), zap.Stringer("seed2", secret))
+
+	entries := readLogEntries(t, out)
+	require.Len(t, entries, 1)
+	require.Equal(t, cmd.RedactedValue, entries[0]["seed"])
+	require.Equal(t, cmd.RedactedValue, entries[0]["seed2"])
+
+	t.Setenv(seedEnvKey, "hex:0")
+
+	_, err = cmd.GetSecretBytes(newCommand(t, seedFlags), seedFlagName, seedEnvKey, cmd.BytesOptions{}, false)
+	require.Error(t, err)
+}
+
+// seedFlags adds the seed flag.
+func seedFlags(command *cobra.Command) error {
+	command.Flags().String(seedFlagName, "", "")
+
+	return nil
+}
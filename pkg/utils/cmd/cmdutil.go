@@ -25,6 +25,12 @@ func GetOptionalString(cmd *cobra.Command, flagName, envKey string) string {
 
 // GetString returns values either command line flag or environment variable.
 func GetString(cmd *cobra.Command, flagName, envKey string, isOptional bool) (string, error) {
+	return getString(cmd, flagName, envKey, isOptional, resolveValue)
+}
+
+// getString returns the value set via either command line flag or environment variable resolved with
+// the given resolver. The raw value is returned if the resolver is nil.
+func getString(cmd *cobra.Command, flagName, envKey string, isOptional bool, resolve valueResolver) (string, error) {
 	if cmd.Flags().Changed(flagName) {
 		value, err := cmd.Flags().GetString(flagName)
 		if err != nil {
@@ -35,7 +41,7 @@ func GetString(cmd *cobra.Command, flagName, envKey string, isOptional bool) (st
 			return "", fmt.Errorf("%s value is empty", flagName)
 		}
 
-		return value, nil
+		return resolveWith(resolve, cmd, flagName, value)
 	}
 
 	value, isSet := os.LookupEnv(envKey)
@@ -45,7 +51,7 @@ func GetString(cmd *cobra.Command, flagName, envKey string, isOptional bool) (st
 			return "", fmt.Errorf("%s value is empty", envKey)
 		}
 
-		return value, nil
+		return resolveWith(resolve, cmd, envKey, value)
 	}
 
 	return "", errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
@@ -61,6 +67,13 @@ func GetOptionalStringArray(cmd *cobra.Command, flagName, envKey string) []strin
 
 // GetStringArray returns values either command line flag or environment variable.
 func GetStringArray(cmd *cobra.Command, flagName, envKey string, isOptional bool) ([]string, error) {
+	return getStringArray(cmd, flagName, envKey, isOptional, resolveValue)
+}
+
+// getStringArray returns the values set via either command line flag or environment variable resolved with
+// the given resolver.
+func getStringArray(cmd *cobra.Command, flagName, envKey string, isOptional bool,
+	resolve valueResolver) ([]string, error) {
 	if cmd.Flags().Changed(flagName) {
 		value, err := cmd.Flags().GetStringArray(flagName)
 		if err != nil {
@@ -71,7 +84,7 @@ func GetStringArray(cmd *cobra.Command, flagName, envKey string, isOptional bool
 			return nil, fmt.Errorf("%s value is empty", flagName)
 		}
 
-		return value, nil
+		return resolveValues(resolve, cmd, flagName, value)
 	}
 
 	value, isSet := os.LookupEnv(envKey)
@@ -85,7 +98,7 @@ func GetStringArray(cmd *cobra.Command, flagName, envKey string, isOptional bool
 			return []string{}, nil
 		}
 
-		return strings.Split(value, ","), nil
+		return resolveValues(resolve, cmd, envKey, strings.Split(value, ","))
 	}
 
 	return nil, errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
@@ -112,6 +125,8 @@ func GetBool(cmd *cobra.Command, flagName, envKey string, defaultValue, isOption
 }
 
 // GetDuration returns values either command line flag or environment variable.
+// The value is parsed with ParseDuration so, in addition to the Go duration syntax, day and week units
+// and ISO-8601 durations are accepted.
 func GetDuration(cmd *cobra.Command, flagName, envKey string,
 	defaultDuration time.Duration, isOptional bool) (time.Duration, error) {
 	timeoutStr, err := GetUserSetVarFromString(cmd, flagName, envKey, isOptional)
@@ -123,7 +138,7 @@ func GetDuration(cmd *cobra.Command, flagName, envKey string,
 		return defaultDuration, nil
 	}
 
-	timeout, err := time.ParseDuration(timeoutStr)
+	timeout, err := ParseDuration(timeoutStr)
 	if err != nil {
 		return -1, fmt.Errorf("invalid value [%s]: %w", timeoutStr, err)
 	}
@@ -190,7 +205,22 @@ type TLSFields struct {
 }
 
 // GetTLS returns values either command line flag or environment variable.
-func GetTLS(cmd *cobra.Command, tlsFields *TLSFields) (*TLSParameters, error) {
+// By default, the TLS parameters are optional and the files aren't verified. The WithStrictTLS,
+// WithRequiredCACerts and WithRequiredCertificate options enable the verification of the TLS files, in which
+// case all of the problems are returned together in a ValidationError. Otherwise, the values which can't be
+// resolved (e.g. which can't be decrypted) are ignored as with the Optional getters. The returned errors
+// identify the field which failed.
+func GetTLS(cmd *cobra.Command, tlsFields *TLSFields, opts ...TLSOption) (*TLSParameters, error) {
+	options := &tlsOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	if options.strict {
+		return getStrictTLS(cmd, tlsFields, options)
+	}
+
 	tlsSystemCertPoolString := GetUserSetOptionalVarFromString(cmd, tlsFields.SystemCertPoolFlagName,
 		tlsFields.SystemCertPoolEnvKey)
 
@@ -201,7 +231,7 @@ func GetTLS(cmd *cobra.Command, tlsFields *TLSFields) (*TLSParameters, error) {
 
 		tlsSystemCertPool, err = strconv.ParseBool(tlsSystemCertPoolString)
 		if err != nil {
-			return nil, err
+			return nil, tlsFieldError(tlsFields.SystemCertPoolFlagName, tlsFields.SystemCertPoolEnvKey, err)
 		}
 	}
 
@@ -203,6 +203,7 @@ func TestGetDuration(t *testing.T) {
 
 		env, err := GetDuration(command, flagName, envKey, defaultDuration, true)
 		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value [not-an-int]")
 		require.Less(t, env, 0*time.Second)
 	})
 
@@ -0,0 +1,148 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// CompletionKind defines how the shell completes the value of a parameter.
+type CompletionKind string
+
+// Supported completion kinds.
+const (
+	// CompleteDefault completes enum and boolean values and leaves other values to the shell.
+	CompleteDefault CompletionKind = ""
+	// CompleteFile completes file paths, optionally filtered by the extensions in Parameter.FileExtensions.
+	CompleteFile CompletionKind = "file"
+	// CompleteDir completes directory paths.
+	CompleteDir CompletionKind = "dir"
+	// CompleteNone disables completion.
+	CompleteNone CompletionKind = "none"
+)
+
+// TLS certificate and key file extensions used for shell completion.
+const (
+	ExtPEM    = "pem"
+	ExtCRT    = "crt"
+	ExtKey    = "key"
+	ExtPKCS12 = "p12"
+)
+
+// RegisterTLSCompletion registers shell completion for the TLS flags of the given command: file path completion
+// filtered by certificate extensions for the CA certs and certificate flags, by key extensions for the key flag,
+// and boolean value completion for the system cert pool flag. Flags with an empty name or which aren't defined
+// on the command are skipped.
+func RegisterTLSCompletion(cmd *cobra.Command, tlsFields *TLSFields) error {
+	fileFlags := []struct {
+		name       string
+		extensions []string
+	}{
+		{tlsFields.CACertsFlagName, []string{ExtPEM, ExtCRT}},
+		{tlsFields.CertificateFlagName, []string{ExtPEM, ExtCRT, ExtPKCS12}},
+		{tlsFields.KeyFlagName, []string{ExtPEM, ExtKey, ExtPKCS12}},
+	}
+
+	for _, f := range fileFlags {
+		flags := flagSetOf(cmd, f.name)
+		if flags == nil {
+			continue
+		}
+
+		if err := cobra.MarkFlagFilename(flags, f.name, f.extensions...); err != nil {
+			return fmt.Errorf("register completion for %s: %w", f.name, err)
+		}
+	}
+
+	if flagSetOf(cmd, tlsFields.SystemCertPoolFlagName) != nil {
+		err := cmd.RegisterFlagCompletionFunc(tlsFields.SystemCertPoolFlagName, boolCompletionFunc)
+		if err != nil {
+			return fmt.Errorf("register completion for %s: %w", tlsFields.SystemCertPoolFlagName, err)
+		}
+	}
+
+	return nil
+}
+
+// RegisterCompletions registers shell completion for the registered parameters which are defined as flags
+// of the given command. Completion is generated by cobra for bash, zsh, fish and PowerShell.
+func (r *Registry) RegisterCompletions(cmd *cobra.Command) error {
+	for _, p := range r.Parameters() {
+		if err := registerCompletion(cmd, p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func registerCompletion(cmd *cobra.Command, p *Parameter) error {
+	flags := flagSetOf(cmd, p.FlagName)
+	if flags == nil {
+		return nil
+	}
+
+	var err error
+
+	switch p.Completion {
+	case CompleteFile:
+		err = cobra.MarkFlagFilename(flags, p.FlagName, p.FileExtensions...)
+	case CompleteDir:
+		err = cobra.MarkFlagDirname(flags, p.FlagName)
+	case CompleteNone:
+		_ = cmd.RegisterFlagCompletionFunc(p.FlagName, cobra.NoFileCompletions) //nolint:errcheck // already registered
+	default:
+		registerValueCompletion(cmd, p)
+	}
+
+	if err != nil {
+		return fmt.Errorf("register completion for %s: %w", p.FlagName, err)
+	}
+
+	return nil
+}
+
+func registerValueCompletion(cmd *cobra.Command, p *Parameter) {
+	var fn func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective)
+
+	if enum := p.enum(); enum != nil {
+		fn = enum.CompletionFunc()
+	} else if p.Type == BoolType {
+		fn = boolCompletionFunc
+	}
+
+	if fn == nil {
+		return
+	}
+
+	// The flag is known to exist so an error is only returned if a completion function has already
+	// been registered for the flag, in which case the existing function is kept.
+	_ = cmd.RegisterFlagCompletionFunc(p.FlagName, fn) //nolint:errcheck // see above
+}
+
+func boolCompletionFunc(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return []string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// flagSetOf returns the flag set of the command (local or persistent) in which the given flag is defined.
+func flagSetOf(cmd *cobra.Command, flagName string) *pflag.FlagSet {
+	if flagName == "" {
+		return nil
+	}
+
+	if cmd.Flags().Lookup(flagName) != nil {
+		return cmd.Flags()
+	}
+
+	if cmd.PersistentFlags().Lookup(flagName) != nil {
+		return cmd.PersistentFlags()
+	}
+
+	return nil
+}
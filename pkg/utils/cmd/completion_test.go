@@ -0,0 +1,134 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegisterTLSCompletion(t *testing.T) {
+	tlsFields := newTestTLSFields()
+
+	root, start := newCompletionCommands()
+
+	start.Flags().String(tlsFields.SystemCertPoolFlagName, "", "")
+	start.Flags().StringArray(tlsFields.CACertsFlagName, nil, "")
+	start.PersistentFlags().String(tlsFields.CertificateFlagName, "", "")
+	// the key flag isn't defined and is skipped
+
+	require.NoError(t, cmd.RegisterTLSCompletion(start, tlsFields))
+
+	out := complete(t, root, "start", "--"+tlsFields.CACertsFlagName, "")
+	require.Equal(t, "pem\ncrt\n"+directive(cobra.ShellCompDirectiveFilterFileExt), out)
+
+	out = complete(t, root, "start", "--"+tlsFields.CertificateFlagName, "")
+	require.Equal(t, "pem\ncrt\np12\n"+directive(cobra.ShellCompDirectiveFilterFileExt), out)
+
+	out = complete(t, root, "start", "--"+tlsFields.SystemCertPoolFlagName, "")
+	require.Equal(t, "true\nfalse\n"+directive(cobra.ShellCompDirectiveNoFileComp), out)
+}
+
+func TestRegistry_RegisterCompletions(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{
+			FlagName: "tls-key", EnvKey: "TEST_TLS_KEY", Completion: cmd.CompleteFile,
+			FileExtensions: []string{cmd.ExtPEM, cmd.ExtKey},
+		},
+		&cmd.Parameter{FlagName: "data-dir", EnvKey: "TEST_DATA_DIR", Completion: cmd.CompleteDir},
+		&cmd.Parameter{FlagName: "api-token", EnvKey: "TEST_API_TOKEN", Completion: cmd.CompleteNone},
+		&cmd.Parameter{FlagName: dbTypeFlagName, EnvKey: dbTypeEnvKey, Enum: []string{"mem", "mongodb"}},
+		&cmd.Parameter{FlagName: "enable-metrics", EnvKey: "TEST_ENABLE_METRICS", Type: cmd.BoolType},
+		&cmd.Parameter{FlagName: "not-defined", EnvKey: "TEST_NOT_DEFINED", Completion: cmd.CompleteDir},
+	))
+
+	t.Run("flags added by registry", func(t *testing.T) {
+		root, start := newCompletionCommands()
+
+		r.AddFlags(start)
+		require.NoError(t, r.RegisterCompletions(start))
+
+		verifyRegistryCompletions(t, root)
+	})
+
+	t.Run("flags added manually", func(t *testing.T) {
+		root, start := newCompletionCommands()
+
+		start.Flags().String("tls-key", "", "")
+		start.Flags().String("data-dir", "", "")
+		start.Flags().String("api-token", "", "")
+		start.PersistentFlags().String(dbTypeFlagName, "", "")
+		start.Flags().String("enable-metrics", "", "")
+
+		require.NoError(t, r.RegisterCompletions(start))
+
+		verifyRegistryCompletions(t, root)
+	})
+
+	t.Run("invalid completion kind", func(t *testing.T) {
+		err := cmd.NewRegistry().Register(&cmd.Parameter{FlagName: "x", EnvKey: "X", Completion: "invalid"})
+		require.EqualError(t, err, "unsupported completion kind [invalid] for parameter x")
+	})
+}
+
+func verifyRegistryCompletions(t *testing.T, root *cobra.Command) {
+	t.Helper()
+
+	out := complete(t, root, "start", "--tls-key", "")
+	require.Equal(t, "pem\nkey\n"+directive(cobra.ShellCompDirectiveFilterFileExt), out)
+
+	out = complete(t, root, "start", "--data-dir", "")
+	require.Equal(t, directive(cobra.ShellCompDirectiveFilterDirs), out)
+
+	out = complete(t, root, "start", "--api-token", "")
+	require.Equal(t, directive(cobra.ShellCompDirectiveNoFileComp), out)
+
+	out = complete(t, root, "start", "--"+dbTypeFlagName, "mo")
+	require.Equal(t, "mongodb\n"+directive(cobra.ShellCompDirectiveNoFileComp), out)
+
+	out = complete(t, root, "start", "--enable-metrics", "")
+	require.Equal(t, "true\nfalse\n"+directive(cobra.ShellCompDirectiveNoFileComp), out)
+}
+
+func newCompletionCommands() (*cobra.Command, *cobra.Command) {
+	root := &cobra.Command{Use: "root"}
+
+	start := &cobra.Command{
+		Use: "start",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	root.AddCommand(start)
+
+	return root, start
+}
+
+func complete(t *testing.T, root *cobra.Command, args ...string) string {
+	t.Helper()
+
+	out := &bytes.Buffer{}
+
+	root.SetOut(out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	require.NoError(t, root.Execute())
+
+	return out.String()
+}
+
+func directive(d cobra.ShellCompDirective) string {
+	return fmt.Sprintf(":%d\n", d)
+}
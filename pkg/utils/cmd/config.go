@@ -0,0 +1,343 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats of the "config print" command.
+const (
+	// PrintFormatTable prints the configuration as a table.
+	PrintFormatTable = "table"
+	// PrintFormatJSON prints the configuration as JSON.
+	PrintFormatJSON = "json"
+	// PrintFormatYAML prints the configuration as YAML.
+	PrintFormatYAML = "yaml"
+)
+
+// Output formats of the "config env" command.
+const (
+	// EnvFormatExport emits "export KEY=value" lines.
+	EnvFormatExport = "export"
+	// EnvFormatKubernetes emits a Kubernetes container env list.
+	EnvFormatKubernetes = "kubernetes"
+)
+
+const (
+	configFormatFlagName      = "format"
+	configShowSecretsFlagName = "show-secrets"
+)
+
+// safeValueChars are the characters of values which are written without quotes in shell and .env files.
+const safeValueChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-.,:/@=+"
+
+// ConfigEntry is the effective value of a parameter as printed by the "config print" command.
+type ConfigEntry struct {
+	FlagName string      `json:"flag" yaml:"flag"`
+	EnvKey   string      `json:"env" yaml:"env"`
+	Source   Source      `json:"source" yaml:"source"`
+	Value    interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// EnvVar is an environment variable of a Kubernetes container.
+type EnvVar struct {
+	Name  string `json:"name" yaml:"name"`
+	Value string `json:"value" yaml:"value"`
+}
+
+// NewConfigCommand returns a "config" command with the following sub-commands which operate on the
+// registered parameters:
+//
+//	config validate - resolves and validates the configuration, including the TLS files, and fails on errors.
+//	config print    - prints the effective values and their sources as a table, JSON or YAML.
+//	config env      - emits the effective values as "export KEY=value" lines or a Kubernetes env list. Secret
+//	                  values are omitted unless --show-secrets is set.
+//
+// The registered parameters (and the TLS parameters if the WithTLS option is provided) are added as persistent
+// flags of the "config" command so that the configuration may be checked with the same flags as the command
+// which uses it.
+func (r *Registry) NewConfigCommand(opts ...ResolveOption) *cobra.Command {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Validates and prints the configuration",
+	}
+
+	r.AddPersistentFlags(configCmd)
+
+	options := &resolveOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var flagsErr error
+
+	if options.tlsFields != nil {
+		flagsErr = addGroupFlags(configCmd, configCmd.PersistentFlags(),
+			NewTLSGroupFromFields(options.tlsFields).Parameters())
+	}
+
+	// The configuration is resolved by the sub-commands so that errors are reported by "config validate"
+	// rather than by a PersistentPreRunE inherited from the root command. Only an invalid TLS parameter
+	// is reported here.
+	configCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		return flagsErr
+	}
+
+	configCmd.AddCommand(r.newConfigValidateCommand(opts), r.newConfigPrintCommand(opts),
+		r.newConfigEnvCommand(opts))
+
+	return configCmd
+}
+
+func (r *Registry) newConfigValidateCommand(opts []ResolveOption) *cobra.Command {
+	return &cobra.Command{
+		Use:          "validate",
+		Short:        "Validates the configuration",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if _, err := r.ValidateConfig(cmd, opts...); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "Configuration is valid.")
+
+			return nil
+		},
+	}
+}
+
+func (r *Registry) newConfigPrintCommand(opts []ResolveOption) *cobra.Command {
+	formats := NewEnum(PrintFormatTable, PrintFormatJSON, PrintFormatYAML).WithAlias("yml", PrintFormatYAML)
+
+	printCmd := &cobra.Command{
+		Use:          "print",
+		Short:        "Prints the effective configuration and the source of each value",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := GetEnum(cmd, configFormatFlagName, "", formats, PrintFormatTable, true)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := r.Resolve(cmd, opts...)
+			if err != nil {
+				return err
+			}
+
+			return PrintConfig(cmd.OutOrStdout(), configEntries(cmd, cfg, opts), format)
+		},
+	}
+
+	//nolint:errcheck // the flag name is unique
+	_ = AddEnumFlag(printCmd, configFormatFlagName, "f", "Output format.", formats)
+
+	return printCmd
+}
+
+func (r *Registry) newConfigEnvCommand(opts []ResolveOption) *cobra.Command {
+	formats := NewEnum(EnvFormatExport, EnvFormatKubernetes).WithAlias("k8s", EnvFormatKubernetes)
+
+	envCmd := &cobra.Command{
+		Use:          "env",
+		Short:        "Emits the effective configuration as environment variables",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := GetEnum(cmd, configFormatFlagName, "", formats, EnvFormatExport, true)
+			if err != nil {
+				return err
+			}
+
+			showSecrets, err := cmd.Flags().GetBool(configShowSecretsFlagName)
+			if err != nil {
+				return err
+			}
+
+			cfg, err := r.Resolve(cmd, opts...)
+			if err != nil {
+				return err
+			}
+
+			return PrintEnv(cmd.OutOrStdout(), envVars(cmd, cfg, opts, showSecrets), format)
+		},
+	}
+
+	//nolint:errcheck // the flag name is unique
+	_ = AddEnumFlag(envCmd, configFormatFlagName, "f", "Output format.", formats)
+	envCmd.Flags().Bool(configShowSecretsFlagName, false,
+		"Emits the secret values. By default they are omitted so that the output may be applied as is.")
+
+	return envCmd
+}
+
+// ValidateConfig resolves the configuration like Resolve and, if the TLS parameters are resolved, also
+// verifies the TLS files as with the WithStrictTLS option. All errors are returned together in a ValidationError.
+func (r *Registry) ValidateConfig(cmd *cobra.Command, opts ...ResolveOption) (*Config, error) {
+	return r.Resolve(cmd, append(opts, func(opts *resolveOptions) {
+		opts.tlsOptions = append(opts.tlsOptions, WithStrictTLS())
+	})...)
+}
+
+// PrintConfig writes the given configuration entries to the given writer in the given format
+// (PrintFormatTable, PrintFormatJSON or PrintFormatYAML).
+func PrintConfig(w io.Writer, entries []*ConfigEntry, format string) error {
+	switch format {
+	case PrintFormatTable:
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+		fmt.Fprintln(tw, "FLAG\tENV\tSOURCE\tVALUE")
+
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.FlagName, e.EnvKey, e.Source, formatValue(e.Value))
+		}
+
+		return tw.Flush()
+	case PrintFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(entries)
+	case PrintFormatYAML:
+		return writeYAML(w, entries)
+	default:
+		return fmt.Errorf("unsupported output format [%s]", format)
+	}
+}
+
+// PrintEnv writes the given environment variables to the given writer in the given format
+// (EnvFormatExport or EnvFormatKubernetes).
+func PrintEnv(w io.Writer, vars []*EnvVar, format string) error {
+	switch format {
+	case EnvFormatExport:
+		var b strings.Builder
+
+		for _, v := range vars {
+			fmt.Fprintf(&b, "export %s=%s\n", v.Name, shellQuote(v.Value))
+		}
+
+		_, err := io.WriteString(w, b.String())
+
+		return err
+	case EnvFormatKubernetes:
+		return writeYAML(w, map[string][]*EnvVar{"env": vars})
+	default:
+		return fmt.Errorf("unsupported output format [%s]", format)
+	}
+}
+
+// configEntries returns the entries of the resolved parameters followed by the TLS parameters (if resolved).
+// Secret values are redacted.
+func configEntries(cmd *cobra.Command, cfg *Config, opts []ResolveOption) []*ConfigEntry {
+	values := append(cfg.Values(), tlsValues(cmd, cfg, opts)...)
+
+	entries := make([]*ConfigEntry, len(values))
+
+	for i, v := range values {
+		var value interface{}
+
+		switch {
+		case v.Secret && formatValue(v.Value) != "":
+			value = RedactedValue
+		case isDuration(v.Value):
+			value = formatValue(v.Value)
+		default:
+			value = v.Value
+		}
+
+		entries[i] = &ConfigEntry{
+			FlagName: v.Parameter.FlagName,
+			EnvKey:   v.Parameter.EnvKey,
+			Source:   v.Source,
+			Value:    value,
+		}
+	}
+
+	return entries
+}
+
+// envVars returns an environment variable for each resolved parameter (including the TLS parameters)
+// which has a non-empty value. Secret values are omitted unless showSecrets is true, rather than redacted, so
+// that applying the variables never sets a placeholder as the actual value.
+func envVars(cmd *cobra.Command, cfg *Config, opts []ResolveOption, showSecrets bool) []*EnvVar {
+	var vars []*EnvVar
+
+	for _, v := range append(cfg.Values(), tlsValues(cmd, cfg, opts)...) {
+		value := formatValue(v.Value)
+		if value == "" || (v.Secret && !showSecrets) {
+			continue
+		}
+
+		vars = append(vars, &EnvVar{Name: v.Parameter.EnvKey, Value: value})
+	}
+
+	return vars
+}
+
+// tlsValues returns the resolved TLS parameters as values of parameters named after the TLS fields.
+func tlsValues(cmd *cobra.Command, cfg *Config, opts []ResolveOption) []*Value {
+	options := &resolveOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	fields, params := options.tlsFields, cfg.TLS()
+	if fields == nil || params == nil {
+		return nil
+	}
+
+	newValue := func(flagName, envKey string, value interface{}) *Value {
+		p := &Parameter{FlagName: flagName, EnvKey: envKey}
+		source, _ := sourceOf(cmd, p)
+
+		return &Value{Parameter: p, Value: value, Source: source, Secret: isSecretValue(cmd, p, source)}
+	}
+
+	return []*Value{
+		newValue(fields.SystemCertPoolFlagName, fields.SystemCertPoolEnvKey, params.SystemCertPool),
+		newValue(fields.CACertsFlagName, fields.CACertsEnvKey, params.CACerts),
+		newValue(fields.CertificateFlagName, fields.CertificateLEnvKey, params.ServeCertPath),
+		newValue(fields.KeyFlagName, fields.KeyEnvKey, params.ServeKeyPath),
+	}
+}
+
+func isDuration(value interface{}) bool {
+	_, ok := value.(time.Duration)
+
+	return ok
+}
+
+func writeYAML(w io.Writer, value interface{}) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(value); err != nil {
+		return err
+	}
+
+	return enc.Close()
+}
+
+// shellQuote quotes the given value for a POSIX shell.
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, safeValueChars) == "" {
+		return s
+	}
+
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,235 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegistry_NewConfigCommand(t *testing.T) {
+	t.Run("validate", func(t *testing.T) {
+		certPath, keyPath := writeTestKeyPair(t)
+
+		out, err := executeConfigCommand(t, "validate", "--host-url", "localhost:8080",
+			"--tls-cacerts", certPath, "--tls-certificate", certPath, "--tls-key", keyPath)
+		require.NoError(t, err)
+		require.Equal(t, "Configuration is valid.\n", out)
+	})
+
+	t.Run("validate errors", func(t *testing.T) {
+		certPath, _ := writeTestKeyPair(t)
+
+		_, err := executeConfigCommand(t, "validate", "--max-connections", "1000",
+			"--tls-cacerts", "missing.pem")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither host-url (command line flag) nor TEST_HOST_URL")
+		require.Contains(t, err.Error(), "must be at most 100")
+
+		_, err = executeConfigCommand(t, "validate", "--host-url", "localhost:8080",
+			"--tls-cacerts", filepath.Join(t.TempDir(), "missing.pem"), "--tls-certificate", certPath)
+
+		var validationErr *cmd.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errors, 2)
+		require.Contains(t, validationErr.Errors[0].Error(), "tls-cacerts (TEST_TLS_CACERTS): CA cert")
+		require.EqualError(t, validationErr.Errors[1],
+			"tls-key (TEST_TLS_KEY): must be set since tls-certificate (TEST_TLS_CERTIFICATE) is set")
+
+		_, err = executeConfigCommand(t, "validate", "--host-url", "localhost:8080",
+			"--tls-certificate", certPath, "--tls-key", certPath)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid key pair")
+	})
+
+	t.Run("print table", func(t *testing.T) {
+		t.Setenv("TEST_PASSWORD", "secret")
+
+		out, err := executeConfigCommand(t, "print", "--host-url", "localhost:8080", "--cas-url", "https://cas1",
+			"--cas-url", "https://cas2")
+		require.NoError(t, err)
+		require.Contains(t, out, "FLAG                ENV                      SOURCE   VALUE\n")
+		require.Contains(t, out, "host-url            TEST_HOST_URL            flag     localhost:8080\n")
+		require.Contains(t, out, "cas-url             TEST_CAS_URL             flag     https://cas1,https://cas2\n")
+		require.Contains(t, out, "timeout             TEST_TIMEOUT             default  1s\n")
+		require.Contains(t, out, "password            TEST_PASSWORD            env      [REDACTED]\n")
+		require.Contains(t, out, "tls-systemcertpool  TEST_TLS_SYSTEMCERTPOOL  default  false\n")
+		require.NotContains(t, out, "secret")
+	})
+
+	t.Run("print JSON", func(t *testing.T) {
+		t.Setenv("TEST_PASSWORD", "secret")
+
+		out, err := executeConfigCommand(t, "print", "--host-url", "localhost:8080", "-f", "json")
+		require.NoError(t, err)
+
+		var entries []*cmd.ConfigEntry
+		require.NoError(t, json.Unmarshal([]byte(out), &entries))
+		require.Len(t, entries, 12)
+		require.Equal(t, &cmd.ConfigEntry{
+			FlagName: "host-url", EnvKey: "TEST_HOST_URL", Source: cmd.SourceFlag, Value: "localhost:8080",
+		}, entries[0])
+		require.Equal(t, true, entries[5].Value)
+		require.Equal(t, "1s", entries[6].Value)
+		require.Equal(t, cmd.RedactedValue, entries[7].Value)
+	})
+
+	t.Run("print YAML", func(t *testing.T) {
+		out, err := executeConfigCommand(t, "print", "--host-url", "localhost:8080", "--format", "yml")
+		require.NoError(t, err)
+		require.Contains(t, out, "- flag: host-url\n  env: TEST_HOST_URL\n  source: flag\n  value: localhost:8080\n")
+
+		var entries []*cmd.ConfigEntry
+		require.NoError(t, yaml.Unmarshal([]byte(out), &entries))
+		require.Len(t, entries, 12)
+	})
+
+	t.Run("print errors", func(t *testing.T) {
+		_, err := executeConfigCommand(t, "print")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither host-url (command line flag) nor TEST_HOST_URL")
+
+		_, err = executeConfigCommand(t, "print", "--host-url", "localhost:8080", "--format", "xml")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "allowed values are table, json, yaml")
+	})
+
+	t.Run("env export", func(t *testing.T) {
+		t.Setenv("TEST_PASSWORD", "secret")
+
+		out, err := executeConfigCommand(t, "env", "--host-url", "localhost:8080",
+			"--cas-url", "https://cas1", "--cas-url", "https://cas2", "--ratio", "0.25")
+		require.NoError(t, err)
+		require.Equal(t, "export TEST_HOST_URL=localhost:8080\n"+
+			"export TEST_DATABASE_TYPE=mem\n"+
+			"export TEST_CAS_URL=https://cas1,https://cas2\n"+
+			"export TEST_MAX_CONNECTIONS=0\n"+
+			"export TEST_RATIO=0.25\n"+
+			"export TEST_ENABLE_METRICS=true\n"+
+			"export TEST_TIMEOUT=1s\n"+
+			"export TEST_TLS_SYSTEMCERTPOOL=false\n", out)
+		require.NotContains(t, out, "TEST_PASSWORD")
+
+		out, err = executeConfigCommand(t, "env", "--host-url", "localhost:8080", "--show-secrets",
+			"--password", "it's a secret")
+		require.NoError(t, err)
+		require.Contains(t, out, "export TEST_PASSWORD='it'\\''s a secret'\n")
+	})
+
+	t.Run("env Kubernetes", func(t *testing.T) {
+		out, err := executeConfigCommand(t, "env", "--host-url", "localhost:8080", "-f", "k8s")
+		require.NoError(t, err)
+		require.Contains(t, out, "env:\n  - name: TEST_HOST_URL\n    value: localhost:8080\n")
+
+		var doc struct {
+			Env []*cmd.EnvVar `yaml:"env"`
+		}
+
+		require.NoError(t, yaml.Unmarshal([]byte(out), &doc))
+		require.Len(t, doc.Env, 7)
+		require.Equal(t, &cmd.EnvVar{Name: "TEST_TIMEOUT", Value: "1s"}, doc.Env[5])
+	})
+
+	t.Run("env errors", func(t *testing.T) {
+		_, err := executeConfigCommand(t, "env")
+		require.Error(t, err)
+
+		_, err = executeConfigCommand(t, "env", "--host-url", "localhost:8080", "--format", "json")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "allowed values are export, kubernetes")
+	})
+
+	t.Run("root PersistentPreRunE is not invoked", func(t *testing.T) {
+		r := newResolveRegistry(t)
+
+		root := &cobra.Command{Use: "orb"}
+		r.Attach(root)
+		root.AddCommand(r.NewConfigCommand())
+
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetErr(&bytes.Buffer{})
+		root.SetArgs([]string{"config", "validate", "--ratio", "-1"})
+
+		err := root.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "must be at least 0")
+	})
+}
+
+func TestPrintConfig(t *testing.T) {
+	err := cmd.PrintConfig(&bytes.Buffer{}, nil, "xml")
+	require.EqualError(t, err, "unsupported output format [xml]")
+
+	err = cmd.PrintEnv(&bytes.Buffer{}, nil, "xml")
+	require.EqualError(t, err, "unsupported output format [xml]")
+}
+
+func executeConfigCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	r := newResolveRegistry(t)
+	require.NoError(t, r.Register(&cmd.Parameter{FlagName: "password", EnvKey: "TEST_PASSWORD", Secret: true}))
+
+	configCmd := r.NewConfigCommand(cmd.WithTLS(newTestTLSFields()))
+
+	out := &bytes.Buffer{}
+	configCmd.SetOut(out)
+	configCmd.SetErr(&bytes.Buffer{})
+	configCmd.SetArgs(args)
+
+	err := configCmd.Execute()
+
+	return out.String(), err
+}
+
+func writeTestKeyPair(t *testing.T) (string, string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IsCA:         true,
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		0o600))
+
+	return certPath, keyPath
+}
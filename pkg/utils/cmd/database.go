@@ -0,0 +1,270 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DatabaseType is the type of a database.
+type DatabaseType string
+
+// Supported database types.
+const (
+	// DatabaseMongoDB is a MongoDB database ("mongodb://" or "mongodb+srv://" URL).
+	DatabaseMongoDB DatabaseType = "mongodb"
+	// DatabasePostgres is a PostgreSQL database ("postgres://" or "postgresql://" URL).
+	DatabasePostgres DatabaseType = "postgres"
+	// DatabaseMem is an in-memory database which doesn't require a URL.
+	DatabaseMem DatabaseType = "mem"
+)
+
+// Names of the database parameters. The flag names and environment variables of a DatabaseGroup are prefixed
+// with the group prefix (e.g. "database-url" and "ORB_DATABASE_URL").
+const (
+	DatabaseTypeName           = "type"
+	DatabaseURLName            = "url"
+	DatabasePrefixName         = "prefix"
+	DatabasePasswordFileName   = "password-file"
+	DatabaseTimeoutName        = "timeout"
+	DatabaseConnectTimeoutName = "connect-timeout"
+	DatabaseMaxPoolSizeName    = "max-pool-size"
+	DatabaseMinPoolSizeName    = "min-pool-size"
+)
+
+// Default values of the database parameters.
+const (
+	DefaultDatabaseTimeout        = 30 * time.Second
+	DefaultDatabaseConnectTimeout = 10 * time.Second
+	DefaultDatabaseMaxPoolSize    = 100
+)
+
+// DatabaseParameters contains the database parameters resolved by a DatabaseGroup.
+type DatabaseParameters struct {
+	// Type is the type of the database.
+	Type DatabaseType
+	// URL is the connection URL including the password loaded from the password file (if any).
+	// It is nil for an in-memory database which was configured without a URL.
+	URL *url.URL
+	// Prefix is the prefix of the database (or collection/table) names.
+	Prefix string
+	// Timeout is the timeout of database operations.
+	Timeout time.Duration
+	// ConnectTimeout is the timeout for establishing a connection.
+	ConnectTimeout time.Duration
+	// MaxPoolSize is the maximum number of connections in the pool.
+	MaxPoolSize int
+	// MinPoolSize is the minimum number of connections in the pool.
+	MinPoolSize int
+}
+
+// ConnectionString returns the connection URL, including credentials, to be passed to the database driver.
+// It must not be logged; use String instead.
+func (p *DatabaseParameters) ConnectionString() string {
+	if p.URL == nil {
+		return ""
+	}
+
+	return p.URL.String()
+}
+
+// RedactedURL returns the connection URL with the password redacted.
+func (p *DatabaseParameters) RedactedURL() string {
+	if p.URL == nil {
+		return ""
+	}
+
+	return p.URL.Redacted()
+}
+
+// String returns a description of the database parameters in which the password is redacted.
+func (p *DatabaseParameters) String() string {
+	return fmt.Sprintf("type=%s url=%s prefix=%s timeout=%s connectTimeout=%s maxPoolSize=%d minPoolSize=%d",
+		p.Type, p.RedactedURL(), p.Prefix, p.Timeout, p.ConnectTimeout, p.MaxPoolSize, p.MinPoolSize)
+}
+
+// DatabaseGroup is the option group of the database parameters.
+type DatabaseGroup struct {
+	prefix GroupPrefix
+}
+
+var _ OptionGroup[*DatabaseParameters] = (*DatabaseGroup)(nil)
+
+// NewDatabaseGroup returns a database option group with the given prefix (e.g. "database-" and
+// "ORB_DATABASE_").
+func NewDatabaseGroup(prefix GroupPrefix) *DatabaseGroup {
+	return &DatabaseGroup{prefix: prefix}
+}
+
+// Parameters returns the database parameters.
+func (g *DatabaseGroup) Parameters() []*Parameter {
+	minPoolSize := 0.0
+
+	return []*Parameter{
+		g.prefix.parameter(DatabaseTypeName, &Parameter{
+			Description: "The type of database to use for storage.",
+			Default:     string(DatabaseMem),
+			Enum:        []string{string(DatabaseMongoDB), string(DatabasePostgres), string(DatabaseMem)},
+			EnumAliases: map[string]string{
+				"mongo":      string(DatabaseMongoDB),
+				"postgresql": string(DatabasePostgres),
+				"memory":     string(DatabaseMem),
+			},
+		}),
+		g.prefix.parameter(DatabaseURLName, &Parameter{
+			Description: "The URL (DSN) of the database. Not needed for the in-memory database.",
+			Secret:      true,
+		}),
+		g.prefix.parameter(DatabasePrefixName, &Parameter{
+			Description: "An optional prefix to be used when creating and retrieving underlying databases.",
+		}),
+		g.prefix.parameter(DatabasePasswordFileName, &Parameter{
+			Description: "The path of a file containing the database password. The password replaces the " +
+				"password of the database URL.",
+			Completion: CompleteFile,
+		}),
+		g.prefix.parameter(DatabaseTimeoutName, &Parameter{
+			Description:    "The timeout of database operations.",
+			Type:           DurationType,
+			Default:        DefaultDatabaseTimeout,
+			DurationBounds: &DurationBounds{Min: time.Millisecond},
+		}),
+		g.prefix.parameter(DatabaseConnectTimeoutName, &Parameter{
+			Description:    "The timeout for establishing a database connection.",
+			Type:           DurationType,
+			Default:        DefaultDatabaseConnectTimeout,
+			DurationBounds: &DurationBounds{Min: time.Millisecond},
+		}),
+		g.prefix.parameter(DatabaseMaxPoolSizeName, &Parameter{
+			Description: "The maximum number of connections in the connection pool.",
+			Type:        IntType,
+			Default:     DefaultDatabaseMaxPoolSize,
+			Minimum:     &minPoolSize,
+		}),
+		g.prefix.parameter(DatabaseMinPoolSizeName, &Parameter{
+			Description: "The minimum number of connections in the connection pool.",
+			Type:        IntType,
+			Minimum:     &minPoolSize,
+		}),
+	}
+}
+
+// AddFlags adds the database flags to the given command.
+func (g *DatabaseGroup) AddFlags(cmd *cobra.Command) error {
+	return addGroupFlags(cmd, cmd.Flags(), g.Parameters())
+}
+
+// Resolve resolves the database parameters. The URL is parsed and the password is loaded from the password
+// file (if set). Errors don't contain the credentials of the URL.
+func (g *DatabaseGroup) Resolve(cmd *cobra.Command) (*DatabaseParameters, error) {
+	cfg, err := resolveGroupConfig(cmd, g.Parameters())
+	if err != nil {
+		return nil, err
+	}
+
+	params := &DatabaseParameters{
+		Type:           DatabaseType(cfg.String(g.prefix.FlagName(DatabaseTypeName))),
+		Prefix:         cfg.String(g.prefix.FlagName(DatabasePrefixName)),
+		Timeout:        cfg.Duration(g.prefix.FlagName(DatabaseTimeoutName)),
+		ConnectTimeout: cfg.Duration(g.prefix.FlagName(DatabaseConnectTimeoutName)),
+		MaxPoolSize:    cfg.Int(g.prefix.FlagName(DatabaseMaxPoolSizeName)),
+		MinPoolSize:    cfg.Int(g.prefix.FlagName(DatabaseMinPoolSizeName)),
+	}
+
+	if rawURL := cfg.String(g.prefix.FlagName(DatabaseURLName)); rawURL != "" {
+		params.URL, err = parseDatabaseURL(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", g.prefix.FlagName(DatabaseURLName), err)
+		}
+	}
+
+	if passwordFile := cfg.String(g.prefix.FlagName(DatabasePasswordFileName)); passwordFile != "" {
+		if err := setDatabasePassword(params.URL, passwordFile); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", g.prefix.FlagName(DatabasePasswordFileName), err)
+		}
+	}
+
+	return params, nil
+}
+
+// Validate verifies that a URL with a scheme matching the database type is set for MongoDB and PostgreSQL
+// and that the pool sizes are consistent.
+func (g *DatabaseGroup) Validate(params *DatabaseParameters) error {
+	var errs []error
+
+	urlName := g.prefix.FlagName(DatabaseURLName)
+
+	schemes := map[DatabaseType][]string{
+		DatabaseMongoDB:  {"mongodb", "mongodb+srv"},
+		DatabasePostgres: {"postgres", "postgresql"},
+	}
+
+	if allowed, ok := schemes[params.Type]; ok {
+		switch {
+		case params.URL == nil:
+			errs = append(errs, fmt.Errorf("%s (%s) must be set for database type %s", urlName,
+				g.prefix.EnvKey(DatabaseURLName), params.Type))
+		case !contains(allowed, params.URL.Scheme):
+			errs = append(errs, fmt.Errorf("invalid value for %s: scheme [%s] is not supported for database "+
+				"type %s, expecting one of %s", urlName, params.URL.Scheme, params.Type, strings.Join(allowed, ", ")))
+		}
+	}
+
+	if params.MinPoolSize > params.MaxPoolSize {
+		errs = append(errs, fmt.Errorf("%s [%d] must not be greater than %s [%d]",
+			g.prefix.FlagName(DatabaseMinPoolSizeName), params.MinPoolSize,
+			g.prefix.FlagName(DatabaseMaxPoolSizeName), params.MaxPoolSize))
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// parseDatabaseURL parses the given database URL. The returned error doesn't contain the URL since it may
+// contain credentials.
+func parseDatabaseURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The parse error isn't wrapped since it may contain part of the credentials.
+		return nil, errors.New("parse database URL: invalid URL")
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, errors.New("database URL must include a scheme and a host")
+	}
+
+	return u, nil
+}
+
+func setDatabasePassword(u *url.URL, passwordFile string) error {
+	if u == nil {
+		return errors.New("the database URL isn't set")
+	}
+
+	if u.User == nil || u.User.Username() == "" {
+		return errors.New("the database URL doesn't include a username")
+	}
+
+	password, err := os.ReadFile(filepath.Clean(passwordFile))
+	if err != nil {
+		return fmt.Errorf("read password file: %w", err)
+	}
+
+	u.User = url.UserPassword(u.User.Username(), strings.TrimRight(string(password), "\r\n"))
+
+	return nil
+}
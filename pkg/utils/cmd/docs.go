@@ -0,0 +1,374 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// DocFormat is the output format of the reference documentation.
+type DocFormat string
+
+// Supported documentation formats.
+const (
+	// DocFormatMarkdown generates Markdown tables.
+	DocFormatMarkdown DocFormat = "markdown"
+	// DocFormatMan generates a man page.
+	DocFormatMan DocFormat = "man"
+	// DocFormatJSON generates JSON.
+	DocFormatJSON DocFormat = "json"
+	// DocFormatDotEnv generates a .env.example file.
+	DocFormatDotEnv DocFormat = "dotenv"
+)
+
+const (
+	docsFormatFlagName = "format"
+	docsOutputFlagName = "output"
+)
+
+// ParameterDoc is the reference documentation of a parameter.
+type ParameterDoc struct {
+	FlagName    string   `json:"flag"`
+	EnvKey      string   `json:"env"`
+	Type        string   `json:"type"`
+	Default     string   `json:"default,omitempty"`
+	Required    bool     `json:"required"`
+	Secret      bool     `json:"secret,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Deprecated  string   `json:"deprecated,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// CommandDoc is the reference documentation of the parameters of a command.
+type CommandDoc struct {
+	Command     string          `json:"command"`
+	Description string          `json:"description,omitempty"`
+	Parameters  []*ParameterDoc `json:"parameters"`
+}
+
+// NewDocsCommand returns a hidden "docs" command which generates the reference documentation of the
+// registered parameters for the command tree to which it is added.
+func (r *Registry) NewDocsCommand() *cobra.Command {
+	formats := NewEnum(string(DocFormatMarkdown), string(DocFormatMan), string(DocFormatJSON),
+		string(DocFormatDotEnv)).WithAlias("md", string(DocFormatMarkdown)).WithAlias(".env", string(DocFormatDotEnv))
+
+	docsCmd := &cobra.Command{
+		Use:    "docs",
+		Short:  "Generates reference documentation of the flags and environment variables",
+		Hidden: true,
+		Args:   cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := GetEnum(cmd, docsFormatFlagName, "", formats, string(DocFormatMarkdown), true)
+			if err != nil {
+				return err
+			}
+
+			output := GetUserSetOptionalVarFromString(cmd, docsOutputFlagName, "")
+			if output == "" {
+				return r.GenerateDocs(cmd.OutOrStdout(), cmd.Root(), DocFormat(format))
+			}
+
+			f, err := os.Create(filepath.Clean(output))
+			if err != nil {
+				return fmt.Errorf("create output file: %w", err)
+			}
+
+			if err := r.GenerateDocs(f, cmd.Root(), DocFormat(format)); err != nil {
+				_ = f.Close() //nolint:errcheck // the generation error is returned
+
+				return err
+			}
+
+			return f.Close()
+		},
+	}
+
+	//nolint:errcheck // the flag names are unique
+	_ = AddEnumFlag(docsCmd, docsFormatFlagName, "f", "Output format.", formats)
+	docsCmd.Flags().StringP(docsOutputFlagName, "o", "", "Output file. Defaults to standard output.")
+
+	return docsCmd
+}
+
+// Docs returns the reference documentation of the registered parameters for each command of the tree
+// starting at the given root command. A parameter is documented under the command which defines its flag.
+// Parameters which aren't defined as flags of any command are documented under the root command.
+func (r *Registry) Docs(root *cobra.Command) []*CommandDoc {
+	documented := make(map[string]bool)
+
+	var docs []*CommandDoc
+
+	walkCommands(root, func(c *cobra.Command) {
+		doc := &CommandDoc{Command: c.CommandPath(), Description: c.Short}
+
+		for _, p := range r.Parameters() {
+			if c.LocalFlags().Lookup(p.FlagName) == nil || documented[p.FlagName] {
+				continue
+			}
+
+			documented[p.FlagName] = true
+			doc.Parameters = append(doc.Parameters, newParameterDoc(p))
+		}
+
+		if len(doc.Parameters) > 0 {
+			docs = append(docs, doc)
+		}
+	})
+
+	var undocumented []*ParameterDoc
+
+	for _, p := range r.Parameters() {
+		if !documented[p.FlagName] {
+			undocumented = append(undocumented, newParameterDoc(p))
+		}
+	}
+
+	if len(undocumented) > 0 {
+		if len(docs) > 0 && docs[0].Command == root.CommandPath() {
+			docs[0].Parameters = append(docs[0].Parameters, undocumented...)
+		} else {
+			docs = append([]*CommandDoc{{
+				Command: root.CommandPath(), Description: root.Short, Parameters: undocumented,
+			}}, docs...)
+		}
+	}
+
+	return docs
+}
+
+// GenerateDocs writes the reference documentation of the registered parameters for the command tree
+// starting at the given root command in the given format.
+func (r *Registry) GenerateDocs(w io.Writer, root *cobra.Command, format DocFormat) error {
+	docs := r.Docs(root)
+
+	switch format {
+	case DocFormatMarkdown:
+		return writeMarkdownDocs(w, docs)
+	case DocFormatMan:
+		return writeManDocs(w, root, docs)
+	case DocFormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(docs)
+	case DocFormatDotEnv:
+		return writeDotEnvDocs(w, docs)
+	default:
+		return fmt.Errorf("unsupported documentation format [%s]", format)
+	}
+}
+
+func walkCommands(c *cobra.Command, fn func(c *cobra.Command)) {
+	if c.Hidden || c.Name() == "help" {
+		return
+	}
+
+	fn(c)
+
+	for _, child := range c.Commands() {
+		walkCommands(child, fn)
+	}
+}
+
+// newParameterDoc returns the documentation of the given parameter. The default value of a secret parameter
+// is redacted.
+func newParameterDoc(p *Parameter) *ParameterDoc {
+	d := formatValue(p.Default)
+	if p.Secret && d != "" {
+		d = RedactedValue
+	}
+
+	return &ParameterDoc{
+		FlagName:    p.FlagName,
+		EnvKey:      p.EnvKey,
+		Type:        string(p.Type),
+		Default:     d,
+		Required:    p.Required,
+		Secret:      p.Secret,
+		Enum:        p.Enum,
+		Deprecated:  p.Deprecated,
+		Description: p.Description,
+	}
+}
+
+// formatValue returns the string representation of a value as it would be set in a flag or
+// environment variable.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []string:
+		return strings.Join(v, ",")
+	case map[string]string:
+		return FormatStringMap(v)
+	case time.Duration:
+		return v.String()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func writeMarkdownDocs(w io.Writer, docs []*CommandDoc) error {
+	var b strings.Builder
+
+	for i, doc := range docs {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		fmt.Fprintf(&b, "## %s\n\n", doc.Command)
+
+		if doc.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", doc.Description)
+		}
+
+		b.WriteString("| Flag | Environment variable | Type | Default | Required | Description |\n")
+		b.WriteString("|------|----------------------|------|---------|----------|-------------|\n")
+
+		for _, p := range doc.Parameters {
+			fmt.Fprintf(&b, "| `--%s` | `%s` | %s | %s | %s | %s |\n",
+				p.FlagName, p.EnvKey, p.Type, markdownCode(escapeMarkdown(p.Default)), yesNo(p.Required),
+				escapeMarkdown(describe(p)))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeManDocs(w io.Writer, root *cobra.Command, docs []*CommandDoc) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, ".TH %s 1\n", escapeRoff(strings.ToUpper(root.Name())))
+	fmt.Fprintf(&b, ".SH NAME\n%s", escapeRoff(root.Name()))
+
+	if root.Short != "" {
+		fmt.Fprintf(&b, " \\- %s", escapeRoff(root.Short))
+	}
+
+	b.WriteString("\n.SH CONFIGURATION\n")
+	b.WriteString("Each parameter may be set with either a command line flag or an environment variable.\n")
+	b.WriteString("The command line flag takes precedence.\n")
+
+	for _, doc := range docs {
+		fmt.Fprintf(&b, ".SS %s\n", escapeRoff(doc.Command))
+
+		for _, p := range doc.Parameters {
+			fmt.Fprintf(&b, ".TP\n\\fB\\-\\-%s\\fR, \\fB%s\\fR\n", escapeRoff(p.FlagName), escapeRoff(p.EnvKey))
+
+			details := []string{"Type: " + p.Type + "."}
+
+			if p.Default != "" {
+				details = append(details, "Default: "+p.Default+".")
+			}
+
+			if p.Required {
+				details = append(details, "Required.")
+			}
+
+			fmt.Fprintf(&b, "%s\n", escapeRoff(strings.TrimSpace(describe(p)+" "+strings.Join(details, " "))))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+func writeDotEnvDocs(w io.Writer, docs []*CommandDoc) error {
+	var b strings.Builder
+
+	for _, doc := range docs {
+		fmt.Fprintf(&b, "# %s\n\n", doc.Command)
+
+		for _, p := range doc.Parameters {
+			if d := describe(p); d != "" {
+				for _, line := range strings.Split(d, "\n") {
+					fmt.Fprintf(&b, "# %s\n", line)
+				}
+			}
+
+			value := dotEnvValue(p.Default)
+			if p.Secret {
+				value = ""
+			}
+
+			if p.Required {
+				fmt.Fprintf(&b, "# (%s, required)\n%s=%s\n\n", p.Type, p.EnvKey, value)
+			} else {
+				fmt.Fprintf(&b, "# (%s, optional)\n#%s=%s\n\n", p.Type, p.EnvKey, value)
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+
+	return err
+}
+
+// describe returns the description of the parameter including the allowed values and deprecation notice.
+func describe(p *ParameterDoc) string {
+	d := p.Description
+
+	if len(p.Enum) > 0 {
+		d += " Allowed values: " + strings.Join(p.Enum, ", ") + "."
+	}
+
+	if p.Deprecated != "" {
+		d += " Deprecated: " + p.Deprecated
+	}
+
+	return strings.TrimSpace(d)
+}
+
+// dotEnvValue returns the given value as it is written in a .env file: values with characters other than
+// letters, digits and common URL and list separators are double-quoted.
+func dotEnvValue(s string) string {
+	if strings.Trim(s, safeValueChars) == "" {
+		return s
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "\n", `\n`).Replace(s) + `"`
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+
+	return "`" + s + "`"
+}
+
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
+
+func escapeRoff(s string) string {
+	s = strings.NewReplacer("\\", "\\e", "-", "\\-").Replace(s)
+
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+
+	return s
+}
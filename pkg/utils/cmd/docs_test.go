@@ -0,0 +1,224 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegistry_Docs(t *testing.T) {
+	r, root := newDocsCommandTree(t)
+
+	docs := r.Docs(root)
+	require.Len(t, docs, 2)
+
+	require.Equal(t, "orb", docs[0].Command)
+	require.Len(t, docs[0].Parameters, 2)
+	require.Equal(t, "log-level", docs[0].Parameters[0].FlagName)
+	require.Equal(t, "env-only", docs[0].Parameters[1].FlagName)
+
+	require.Equal(t, "orb start", docs[1].Command)
+	require.Equal(t, "Starts the server", docs[1].Description)
+	require.Len(t, docs[1].Parameters, 3)
+	require.Equal(t, &cmd.ParameterDoc{
+		FlagName:    "host-url",
+		EnvKey:      "ORB_HOST_URL",
+		Type:        "string",
+		Required:    true,
+		Description: "URL to run the service on.",
+	}, docs[1].Parameters[0])
+	require.Equal(t, "30s", docs[1].Parameters[1].Default)
+	require.Equal(t, "https://cas1,https://cas2", docs[1].Parameters[2].Default)
+}
+
+func TestRegistry_GenerateDocs(t *testing.T) {
+	r, root := newDocsCommandTree(t)
+
+	t.Run("markdown", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		require.NoError(t, r.GenerateDocs(out, root, cmd.DocFormatMarkdown))
+		require.Contains(t, out.String(), "## orb start\n\nStarts the server\n\n| Flag |")
+		require.Contains(t, out.String(),
+			"| `--host-url` | `ORB_HOST_URL` | string |  | yes | URL to run the service on. |\n")
+		require.Contains(t, out.String(),
+			"| `--timeout` | `ORB_TIMEOUT` | duration | `30s` | no | Request timeout. Deprecated: use request-timeout. |")
+		require.Contains(t, out.String(), "Allowed values: debug, info\\|warn.")
+		require.Contains(t, out.String(), "| `--log-level` | `ORB_LOG_LEVEL` | string | `info\\|warn` | no |")
+	})
+
+	t.Run("man", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		require.NoError(t, r.GenerateDocs(out, root, cmd.DocFormatMan))
+		require.Contains(t, out.String(), ".TH ORB 1\n.SH NAME\norb \\- Orb server\n")
+		require.Contains(t, out.String(), ".SS orb start\n.TP\n\\fB\\-\\-host\\-url\\fR, \\fBORB_HOST_URL\\fR\n")
+		require.Contains(t, out.String(), "URL to run the service on. Type: string. Required.\n")
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		require.NoError(t, r.GenerateDocs(out, root, cmd.DocFormatJSON))
+
+		var docs []*cmd.CommandDoc
+		require.NoError(t, json.Unmarshal(out.Bytes(), &docs))
+		require.Equal(t, r.Docs(root), docs)
+	})
+
+	t.Run("dotenv", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		require.NoError(t, r.GenerateDocs(out, root, cmd.DocFormatDotEnv))
+		require.Contains(t, out.String(),
+			"# orb start\n\n# URL to run the service on.\n# (string, required)\nORB_HOST_URL=\n")
+		require.Contains(t, out.String(), "# (duration, optional)\n#ORB_TIMEOUT=30s\n")
+	})
+
+	t.Run("unsupported format", func(t *testing.T) {
+		err := r.GenerateDocs(&bytes.Buffer{}, root, "html")
+		require.EqualError(t, err, "unsupported documentation format [html]")
+	})
+}
+
+func TestRegistry_GenerateDocsValues(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "db-password", EnvKey: "ORB_DB_PASSWORD", Secret: true, Default: "s3cret"},
+		&cmd.Parameter{FlagName: "greeting", EnvKey: "ORB_GREETING", Default: `say "hi" # $USER`},
+	))
+
+	root := &cobra.Command{Use: "orb"}
+	root.Flags().String("db-password", "", "")
+	root.Flags().String("greeting", "", "")
+
+	docs := r.Docs(root)
+	require.Len(t, docs, 1)
+	require.Equal(t, cmd.RedactedValue, docs[0].Parameters[0].Default)
+	require.True(t, docs[0].Parameters[0].Secret)
+
+	for _, format := range []cmd.DocFormat{cmd.DocFormatMarkdown, cmd.DocFormatMan, cmd.DocFormatJSON} {
+		out := &bytes.Buffer{}
+
+		require.NoError(t, r.GenerateDocs(out, root, format))
+		require.NotContains(t, out.String(), "s3cret")
+	}
+
+	out := &bytes.Buffer{}
+
+	require.NoError(t, r.GenerateDocs(out, root, cmd.DocFormatDotEnv))
+	require.NotContains(t, out.String(), "s3cret")
+	require.Contains(t, out.String(), "#ORB_DB_PASSWORD=\n")
+	require.Contains(t, out.String(), `#ORB_GREETING="say \"hi\" # \$USER"`+"\n")
+}
+
+func TestRegistry_NewDocsCommand(t *testing.T) {
+	r, root := newDocsCommandTree(t)
+
+	docsCmd := r.NewDocsCommand()
+	require.True(t, docsCmd.Hidden)
+
+	root.AddCommand(docsCmd)
+
+	t.Run("standard output", func(t *testing.T) {
+		out := &bytes.Buffer{}
+
+		root.SetOut(out)
+		root.SetArgs([]string{"docs", "--format", "md"})
+		require.NoError(t, root.Execute())
+		require.Contains(t, out.String(), "## orb start")
+		require.NotContains(t, out.String(), "orb docs")
+	})
+
+	t.Run("output file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), ".env.example")
+
+		root.SetArgs([]string{"docs", "-f", "dotenv", "-o", file})
+		require.NoError(t, root.Execute())
+
+		content, err := os.ReadFile(file) //nolint:gosec // test file
+		require.NoError(t, err)
+		require.Contains(t, string(content), "ORB_HOST_URL=")
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		root.SetArgs([]string{"docs", "-f", "html", "-o", ""})
+		err := root.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "allowed values are markdown, man, json, dotenv")
+	})
+
+	t.Run("invalid output file", func(t *testing.T) {
+		root.SetArgs([]string{"docs", "-f", "json", "-o", filepath.Join(t.TempDir(), "x", "y")})
+		err := root.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "create output file")
+	})
+}
+
+func newDocsCommandTree(t *testing.T) (*cmd.Registry, *cobra.Command) {
+	t.Helper()
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{
+			FlagName: "log-level", EnvKey: "ORB_LOG_LEVEL", Enum: []string{"debug", "info|warn"}, Default: "info|warn",
+		},
+		&cmd.Parameter{
+			FlagName: "host-url", EnvKey: "ORB_HOST_URL", Required: true, Description: "URL to run the service on.",
+		},
+		&cmd.Parameter{
+			FlagName: "timeout", EnvKey: "ORB_TIMEOUT", Type: cmd.DurationType, Default: 30 * time.Second,
+			Description: "Request timeout.", Deprecated: "use request-timeout.",
+		},
+		&cmd.Parameter{
+			FlagName: "cas-url", EnvKey: "ORB_CAS_URL", Type: cmd.StringArrayType,
+			Default: []string{"https://cas1", "https://cas2"},
+		},
+		&cmd.Parameter{FlagName: "env-only", EnvKey: "ORB_ENV_ONLY"},
+	))
+
+	root := &cobra.Command{Use: "orb", Short: "Orb server"}
+	root.PersistentFlags().String("log-level", "", "")
+
+	start := &cobra.Command{
+		Use:   "start",
+		Short: "Starts the server",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	start.Flags().String("host-url", "", "")
+	start.Flags().String("timeout", "", "")
+	start.Flags().StringArray("cas-url", nil, "")
+
+	hidden := &cobra.Command{
+		Use:    "hidden",
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	hidden.Flags().String("host-url", "", "")
+
+	root.AddCommand(start, hidden)
+
+	return r, root
+}
@@ -0,0 +1,230 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// Day is the duration of a day unit ("d").
+	Day = 24 * time.Hour
+	// Week is the duration of a week unit ("w").
+	Week = 7 * Day
+)
+
+var isoDurationRegex = regexp.MustCompile(
+	`^P(?:(\d+(?:[.,]\d+)?)Y)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)W)?(?:(\d+(?:[.,]\d+)?)D)?` +
+		`(?:T(?:(\d+(?:[.,]\d+)?)H)?(?:(\d+(?:[.,]\d+)?)M)?(?:(\d+(?:[.,]\d+)?)S)?)?$`)
+
+// DurationBounds specifies the range of allowed duration values.
+type DurationBounds struct {
+	// Min is the minimum allowed value (inclusive). The zero value rejects negative durations.
+	Min time.Duration
+	// Max is the maximum allowed value (inclusive). The zero value means that there is no upper bound.
+	Max time.Duration
+}
+
+// ParseDuration parses a duration string. In addition to the syntax accepted by time.ParseDuration,
+// the "d" (day) and "w" (week) units are supported (e.g. "7d" or "1w2d12h") as well as ISO-8601
+// durations (e.g. "PT15M" or "P1DT12H"). Years and months are not supported since their length varies.
+func ParseDuration(s string) (time.Duration, error) {
+	value := strings.TrimSpace(s)
+
+	sign := time.Duration(1)
+	unsigned := value
+
+	switch {
+	case strings.HasPrefix(value, "-"):
+		sign, unsigned = -1, value[1:]
+	case strings.HasPrefix(value, "+"):
+		unsigned = value[1:]
+	}
+
+	if strings.HasPrefix(unsigned, "-") || strings.HasPrefix(unsigned, "+") {
+		return 0, fmt.Errorf("time: invalid duration %q: more than one sign", s)
+	}
+
+	if strings.HasPrefix(unsigned, "P") {
+		d, err := parseISODuration(unsigned)
+		if err != nil {
+			return 0, err
+		}
+
+		return sign * d, nil
+	}
+
+	if !strings.ContainsAny(unsigned, "dw") {
+		return time.ParseDuration(value)
+	}
+
+	d, err := parseExtendedDuration(unsigned)
+	if err != nil {
+		return 0, fmt.Errorf("time: invalid duration %q: %w", s, err)
+	}
+
+	return sign * d, nil
+}
+
+// parseExtendedDuration parses an unsigned Go duration which may contain day and week units.
+func parseExtendedDuration(s string) (time.Duration, error) {
+	var total time.Duration
+
+	for s != "" {
+		i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if i <= 0 {
+			return 0, errors.New("expected number followed by unit")
+		}
+
+		number := s[:i]
+		s = s[i:]
+
+		j := strings.IndexFunc(s, func(r rune) bool { return (r >= '0' && r <= '9') || r == '.' })
+		if j < 0 {
+			j = len(s)
+		}
+
+		unit := s[:j]
+		s = s[j:]
+
+		var (
+			d   time.Duration
+			err error
+		)
+
+		switch unit {
+		case "d":
+			d, err = multiply(number, Day)
+		case "w":
+			d, err = multiply(number, Week)
+		default:
+			d, err = time.ParseDuration(number + unit)
+		}
+
+		if err != nil {
+			return 0, err
+		}
+
+		total, err = add(total, d)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return total, nil
+}
+
+func parseISODuration(s string) (time.Duration, error) {
+	matches := isoDurationRegex.FindStringSubmatch(s)
+	if matches == nil || s == "P" || strings.HasSuffix(s, "T") {
+		return 0, fmt.Errorf("time: invalid ISO-8601 duration %q", s)
+	}
+
+	if matches[1] != "" || matches[2] != "" {
+		return 0, fmt.Errorf("time: invalid ISO-8601 duration %q: years and months are not supported", s)
+	}
+
+	units := []time.Duration{0, 0, 0, Week, Day, time.Hour, time.Minute, time.Second}
+
+	var total time.Duration
+
+	for i := 3; i < len(matches); i++ {
+		if matches[i] == "" {
+			continue
+		}
+
+		d, err := multiply(strings.Replace(matches[i], ",", ".", 1), units[i])
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid ISO-8601 duration %q: %w", s, err)
+		}
+
+		total, err = add(total, d)
+		if err != nil {
+			return 0, fmt.Errorf("time: invalid ISO-8601 duration %q: %w", s, err)
+		}
+	}
+
+	return total, nil
+}
+
+func multiply(number string, unit time.Duration) (time.Duration, error) {
+	n, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", number)
+	}
+
+	// float64(math.MaxInt64) is rounded up to 2^63, which doesn't fit in a time.Duration.
+	d := n * float64(unit)
+	if d >= math.MaxInt64 {
+		return 0, errors.New("duration out of range")
+	}
+
+	return time.Duration(d), nil
+}
+
+func add(a, b time.Duration) (time.Duration, error) {
+	if a > math.MaxInt64-b {
+		return 0, errors.New("duration out of range")
+	}
+
+	return a + b, nil
+}
+
+// GetBoundedDuration returns values either command line flag or environment variable. The value is parsed
+// with ParseDuration and must be within the given bounds.
+func GetBoundedDuration(cmd *cobra.Command, flagName, envKey string, defaultDuration time.Duration,
+	bounds DurationBounds, isOptional bool) (time.Duration, error) {
+	return getDuration(cmd, flagName, envKey, defaultDuration, &bounds, isOptional)
+}
+
+// getDuration returns the duration set with the given command line flag or environment variable. The value
+// must be within the given bounds (if any). Errors name both the flag and the environment variable.
+func getDuration(cmd *cobra.Command, flagName, envKey string, defaultDuration time.Duration,
+	bounds *DurationBounds, isOptional bool) (time.Duration, error) {
+	str, err := GetUserSetVarFromString(cmd, flagName, envKey, isOptional)
+	if err != nil {
+		return -1, err
+	}
+
+	if str == "" {
+		return defaultDuration, nil
+	}
+
+	value, err := ParseDuration(str)
+	if err != nil {
+		return -1, fmt.Errorf("invalid value for %s (%s) [%s]: %w", flagName, envKey, str, err)
+	}
+
+	if bounds == nil {
+		return value, nil
+	}
+
+	if err := bounds.check(value); err != nil {
+		return -1, fmt.Errorf("invalid value for %s (%s) [%s]: %w", flagName, envKey, value, err)
+	}
+
+	return value, nil
+}
+
+func (b DurationBounds) check(value time.Duration) error {
+	if value < b.Min {
+		return fmt.Errorf("must be at least %s", b.Min)
+	}
+
+	if b.Max > 0 && value > b.Max {
+		return fmt.Errorf("must be at most %s", b.Max)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestParseDuration(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		tests := map[string]time.Duration{
+			"15s":          15 * time.Second,
+			"1h30m":        90 * time.Minute,
+			"-2m":          -2 * time.Minute,
+			"7d":           7 * cmd.Day,
+			"1.5d":         36 * time.Hour,
+			"1w2d12h":      cmd.Week + 2*cmd.Day + 12*time.Hour,
+			"-1d":          -cmd.Day,
+			"2w30m":        2*cmd.Week + 30*time.Minute,
+			"PT15M":        15 * time.Minute,
+			"P1DT12H":      36 * time.Hour,
+			"P2W":          2 * cmd.Week,
+			"PT0.5S":       500 * time.Millisecond,
+			"PT1,5H":       90 * time.Minute,
+			"-PT1M":        -time.Minute,
+			"P1W1DT1H1M1S": cmd.Week + cmd.Day + time.Hour + time.Minute + time.Second,
+		}
+
+		for s, expected := range tests {
+			d, err := cmd.ParseDuration(s)
+			require.NoErrorf(t, err, "parse %s", s)
+			require.Equalf(t, expected, d, "parse %s", s)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		tests := map[string]string{
+			"":                        "invalid duration",
+			"xxx":                     "invalid duration",
+			"7dd":                     "invalid duration",
+			"d":                       "expected number followed by unit",
+			"1.2.3d":                  "invalid number",
+			"10x2d":                   "unknown unit",
+			"P":                       "invalid ISO-8601 duration",
+			"PT":                      "invalid ISO-8601 duration",
+			"P1DT":                    "invalid ISO-8601 duration",
+			"P1H":                     "invalid ISO-8601 duration",
+			"P1Y":                     "years and months are not supported",
+			"P1M":                     "years and months are not supported",
+			"P99999999W":              "duration out of range",
+			"99999999w":               "duration out of range",
+			"PT9223372036.854775808S": "duration out of range",
+			"106751.99116730064d":     "duration out of range",
+			"+-1d":                    "more than one sign",
+			"-+1d":                    "more than one sign",
+			"--1s":                    "more than one sign",
+			"+-PT1M":                  "more than one sign",
+		}
+
+		for s, errMsg := range tests {
+			_, err := cmd.ParseDuration(s)
+			require.Errorf(t, err, "parse %s", s)
+			require.Containsf(t, err.Error(), errMsg, "parse %s", s)
+		}
+	})
+}
+
+func TestGetBoundedDuration(t *testing.T) {
+	const (
+		flag = "token-lifetime"
+		env  = "TEST_TOKEN_LIFETIME"
+	)
+
+	command := &cobra.Command{Use: "start"}
+
+	bounds := cmd.DurationBounds{Min: time.Minute, Max: 30 * cmd.Day}
+
+	t.Run("default", func(t *testing.T) {
+		d, err := cmd.GetBoundedDuration(command, flag, env, time.Hour, bounds, true)
+		require.NoError(t, err)
+		require.Equal(t, time.Hour, d)
+	})
+
+	t.Run("within bounds", func(t *testing.T) {
+		t.Setenv(env, "7d")
+
+		d, err := cmd.GetBoundedDuration(command, flag, env, time.Hour, bounds, true)
+		require.NoError(t, err)
+		require.Equal(t, 7*cmd.Day, d)
+	})
+
+	t.Run("below minimum", func(t *testing.T) {
+		t.Setenv(env, "PT30S")
+
+		d, err := cmd.GetBoundedDuration(command, flag, env, time.Hour, bounds, true)
+		require.EqualError(t, err, "invalid value for token-lifetime (TEST_TOKEN_LIFETIME) [30s]: must be at least 1m0s")
+		require.Less(t, d, time.Duration(0))
+	})
+
+	t.Run("above maximum", func(t *testing.T) {
+		t.Setenv(env, "5w")
+
+		_, err := cmd.GetBoundedDuration(command, flag, env, time.Hour, bounds, true)
+		require.EqualError(t, err,
+			"invalid value for token-lifetime (TEST_TOKEN_LIFETIME) [840h0m0s]: must be at most 720h0m0s")
+	})
+
+	t.Run("negative value with zero bounds", func(t *testing.T) {
+		t.Setenv(env, "-1s")
+
+		_, err := cmd.GetBoundedDuration(command, flag, env, time.Hour, cmd.DurationBounds{}, true)
+		require.EqualError(t, err, "invalid value for token-lifetime (TEST_TOKEN_LIFETIME) [-1s]: must be at least 0s")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv(env, "xxx")
+
+		_, err := cmd.GetBoundedDuration(command, flag, env, time.Hour, bounds, true)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for token-lifetime (TEST_TOKEN_LIFETIME) [xxx]")
+	})
+}
+
+func TestRegistry_ResolveBoundedDuration(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(&cmd.Parameter{
+		FlagName:       "token-lifetime",
+		EnvKey:         "TEST_TOKEN_LIFETIME",
+		Type:           cmd.DurationType,
+		Default:        time.Hour,
+		DurationBounds: &cmd.DurationBounds{Max: cmd.Week},
+	}))
+
+	command := &cobra.Command{Use: "start"}
+
+	t.Setenv("TEST_TOKEN_LIFETIME", "P1D")
+
+	cfg, err := r.Resolve(command)
+	require.NoError(t, err)
+	require.Equal(t, cmd.Day, cfg.Duration("token-lifetime"))
+
+	t.Setenv("TEST_TOKEN_LIFETIME", "2w")
+
+	_, err = r.Resolve(command)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "invalid value for token-lifetime (TEST_TOKEN_LIFETIME) [336h0m0s]")
+
+	err = cmd.NewRegistry().Register(&cmd.Parameter{
+		FlagName:       "token-lifetime",
+		EnvKey:         "TEST_TOKEN_LIFETIME",
+		Type:           cmd.DurationType,
+		DurationBounds: &cmd.DurationBounds{Min: cmd.Week, Max: cmd.Day},
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "minimum duration is greater than maximum duration")
+}
@@ -0,0 +1,313 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// EncryptedValuePrefix is the prefix of encrypted values. An encrypted value may be used in any flag,
+	// environment variable or configuration file and is decrypted transparently by the getters.
+	EncryptedValuePrefix = "enc:v1:"
+
+	// EncryptionKeyEnvKey is the environment variable which contains the base64 encoded encryption key.
+	EncryptionKeyEnvKey = "CONFIG_ENCRYPTION_KEY"
+	// EncryptionKeyFileEnvKey is the environment variable which contains the path of a file containing
+	// the base64 encoded encryption key.
+	EncryptionKeyFileEnvKey = "CONFIG_ENCRYPTION_KEY_FILE"
+
+	// EncryptionKeySize is the size of the AES-256 encryption key.
+	EncryptionKeySize = 32
+
+	keyFlagName     = "key"
+	keyFileFlagName = "key-file"
+	newKeyFlagName  = "new-key"
+	newKeyFileFlag  = "new-key-file"
+)
+
+// IsEncryptedValue returns true if the given value is encrypted.
+func IsEncryptedValue(value string) bool {
+	return strings.HasPrefix(value, EncryptedValuePrefix)
+}
+
+// GenerateEncryptionKey returns a new random encryption key.
+func GenerateEncryptionKey() ([]byte, error) {
+	key := make([]byte, EncryptionKeySize)
+
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+
+	return key, nil
+}
+
+// LoadEncryptionKey loads the encryption key from the CONFIG_ENCRYPTION_KEY environment variable or, if not set,
+// from the file referenced by the CONFIG_ENCRYPTION_KEY_FILE environment variable.
+func LoadEncryptionKey() ([]byte, error) {
+	if encoded := os.Getenv(EncryptionKeyEnvKey); encoded != "" {
+		return ParseEncryptionKey(encoded)
+	}
+
+	if keyFile := os.Getenv(EncryptionKeyFileEnvKey); keyFile != "" {
+		return ReadEncryptionKeyFile(keyFile)
+	}
+
+	return nil, fmt.Errorf("neither %s nor %s (environment variable) have been set",
+		EncryptionKeyEnvKey, EncryptionKeyFileEnvKey)
+}
+
+// ReadEncryptionKeyFile reads the base64 encoded encryption key from the given file.
+func ReadEncryptionKeyFile(keyFile string) ([]byte, error) {
+	encoded, err := os.ReadFile(filepath.Clean(keyFile))
+	if err != nil {
+		return nil, fmt.Errorf("read encryption key file: %w", err)
+	}
+
+	return ParseEncryptionKey(string(encoded))
+}
+
+// ParseEncryptionKey parses a base64 encoded encryption key.
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+
+	if len(key) != EncryptionKeySize {
+		return nil, fmt.Errorf("invalid encryption key size %d: expecting %d bytes", len(key), EncryptionKeySize)
+	}
+
+	return key, nil
+}
+
+// EncryptValue encrypts the given value with AES-256-GCM and returns it in the form "enc:v1:<base64>".
+func EncryptValue(key []byte, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), []byte(EncryptedValuePrefix))
+
+	return EncryptedValuePrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptValue decrypts a value of the form "enc:v1:<base64>" which was encrypted with EncryptValue.
+func DecryptValue(key []byte, value string) (string, error) {
+	if !IsEncryptedValue(value) {
+		return "", errors.New("value is not encrypted")
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, EncryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode encrypted value: %w", err)
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("decrypt value: ciphertext too short")
+	}
+
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():],
+		[]byte(EncryptedValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("decrypt value: %w", err)
+	}
+
+	return string(plaintext), nil
+}
+
+// RotateValue decrypts the given encrypted value with the old key and encrypts it with the new key.
+func RotateValue(oldKey, newKey []byte, value string) (string, error) {
+	plaintext, err := DecryptValue(oldKey, value)
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptValue(newKey, plaintext)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create cipher: %w", err)
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// NewSecretCommand returns a "secret" command with sub-commands to generate encryption keys and to encrypt,
+// decrypt and rotate configuration values. The key is provided with the --key or --key-file flag or with the
+// CONFIG_ENCRYPTION_KEY or CONFIG_ENCRYPTION_KEY_FILE environment variable.
+func NewSecretCommand() *cobra.Command {
+	secretCmd := &cobra.Command{
+		Use:   "secret",
+		Short: "Manages encrypted configuration values",
+	}
+
+	secretCmd.PersistentFlags().String(keyFlagName, "", "Base64 encoded encryption key. Alternatively, this can "+
+		"be set with the following environment variable: "+EncryptionKeyEnvKey)
+	secretCmd.PersistentFlags().String(keyFileFlagName, "", "File containing the base64 encoded encryption key. "+
+		"Alternatively, this can be set with the following environment variable: "+EncryptionKeyFileEnvKey)
+
+	secretCmd.AddCommand(
+		newGenerateKeyCommand(),
+		newEncryptCommand(),
+		newDecryptCommand(),
+		newRotateCommand(),
+	)
+
+	return secretCmd
+}
+
+func newGenerateKeyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "generate-key",
+		Short: "Generates a new base64 encoded encryption key",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := GenerateEncryptionKey()
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), base64.StdEncoding.EncodeToString(key))
+
+			return err
+		},
+	}
+}
+
+func newEncryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "encrypt",
+		Short: "Encrypts the value read from standard input",
+		Long: "Encrypts the value read from standard input (e.g. a multi-line PEM file) so that it doesn't " +
+			"appear in the command line. Trailing line breaks are removed.",
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := keyFromCommand(cmd, keyFlagName, keyFileFlagName)
+			if err != nil {
+				return err
+			}
+
+			return transformValues(cmd, args, func(value string) (string, error) {
+				return EncryptValue(key, value)
+			})
+		},
+	}
+}
+
+func newDecryptCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "decrypt [value...]",
+		Short: "Decrypts the given values (or the value read from standard input if no value is given)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key, err := keyFromCommand(cmd, keyFlagName, keyFileFlagName)
+			if err != nil {
+				return err
+			}
+
+			return transformValues(cmd, args, func(value string) (string, error) {
+				return DecryptValue(key, value)
+			})
+		},
+	}
+}
+
+func newRotateCommand() *cobra.Command {
+	rotateCmd := &cobra.Command{
+		Use: "rotate [value...]",
+		Short: "Re-encrypts the given values (or the value read from standard input if no value is given) " +
+			"with a new key",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			oldKey, err := keyFromCommand(cmd, keyFlagName, keyFileFlagName)
+			if err != nil {
+				return err
+			}
+
+			newKey, err := keyFromCommand(cmd, newKeyFlagName, newKeyFileFlag)
+			if err != nil {
+				return err
+			}
+
+			return transformValues(cmd, args, func(value string) (string, error) {
+				return RotateValue(oldKey, newKey, value)
+			})
+		},
+	}
+
+	rotateCmd.Flags().String(newKeyFlagName, "", "Base64 encoded new encryption key.")
+	rotateCmd.Flags().String(newKeyFileFlag, "", "File containing the base64 encoded new encryption key.")
+
+	return rotateCmd
+}
+
+func keyFromCommand(cmd *cobra.Command, keyFlag, keyFileFlag string) ([]byte, error) {
+	if encoded := GetUserSetOptionalVarFromString(cmd, keyFlag, ""); encoded != "" {
+		return ParseEncryptionKey(encoded)
+	}
+
+	if keyFile := GetUserSetOptionalVarFromString(cmd, keyFileFlag, ""); keyFile != "" {
+		return ReadEncryptionKeyFile(keyFile)
+	}
+
+	if keyFlag == newKeyFlagName {
+		return nil, fmt.Errorf("neither %s nor %s (command line flag) have been set", keyFlag, keyFileFlag)
+	}
+
+	return LoadEncryptionKey()
+}
+
+// transformValues transforms each of the given values or, if none is given, the whole of standard input as
+// a single value.
+func transformValues(cmd *cobra.Command, args []string, transform func(string) (string, error)) error {
+	values := args
+
+	if len(values) == 0 {
+		value, err := readValueFile(cmd, "value", "-")
+		if err != nil {
+			return err
+		}
+
+		values = []string{value}
+	}
+
+	for _, v := range values {
+		result, err := transform(v)
+		if err != nil {
+			return err
+		}
+
+		if _, err := fmt.Fprintln(cmd.OutOrStdout(), result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
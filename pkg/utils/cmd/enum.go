@@ -0,0 +1,126 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Enum defines the allowed values of an enumerated parameter. Values are matched case-insensitively
+// and may have aliases (e.g. "mongo" for "mongodb").
+type Enum struct {
+	values  []string
+	lookup  map[string]string
+	aliases map[string]string
+}
+
+// NewEnum returns an Enum with the given allowed values.
+func NewEnum(values ...string) *Enum {
+	e := &Enum{
+		lookup:  make(map[string]string),
+		aliases: make(map[string]string),
+	}
+
+	for _, v := range values {
+		e.values = append(e.values, v)
+		e.lookup[strings.ToLower(v)] = v
+	}
+
+	return e
+}
+
+// WithAlias adds an alias for the given allowed value and returns the Enum.
+func (e *Enum) WithAlias(alias, value string) *Enum {
+	e.aliases[strings.ToLower(alias)] = value
+
+	return e
+}
+
+// Values returns the allowed values.
+func (e *Enum) Values() []string {
+	values := make([]string, len(e.values))
+	copy(values, e.values)
+
+	return values
+}
+
+// Match returns the allowed value which matches the given value (or one of its aliases) case-insensitively.
+// The allowed value is returned as declared, also when an alias matches.
+func (e *Enum) Match(value string) (string, bool) {
+	key := strings.ToLower(value)
+
+	if alias, ok := e.aliases[key]; ok {
+		key = strings.ToLower(alias)
+	}
+
+	v, ok := e.lookup[key]
+
+	return v, ok
+}
+
+// String returns the allowed values as a comma separated list.
+func (e *Enum) String() string {
+	return strings.Join(e.values, ", ")
+}
+
+// Usage returns the given usage text appended with the list of allowed values.
+func (e *Enum) Usage(usage string) string {
+	return strings.TrimSpace(fmt.Sprintf("%s Allowed values: %s.", usage, e))
+}
+
+// CompletionFunc returns a cobra completion function which completes the allowed values.
+func (e *Enum) CompletionFunc() func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var matches []string
+
+		for _, v := range e.values {
+			if strings.HasPrefix(strings.ToLower(v), strings.ToLower(toComplete)) {
+				matches = append(matches, v)
+			}
+		}
+
+		return matches, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// AddEnumFlag adds a string flag with the given name to the command. The allowed values are listed in
+// the usage text of the flag and shell completion is registered for them.
+func AddEnumFlag(cmd *cobra.Command, flagName, shorthand, usage string, enum *Enum) error {
+	cmd.Flags().StringP(flagName, shorthand, "", enum.Usage(usage))
+
+	return cmd.RegisterFlagCompletionFunc(flagName, enum.CompletionFunc())
+}
+
+// GetOptionalEnum returns values either command line flag or environment variable. The default value is
+// returned if the value isn't set and an error is returned if the value isn't one of the allowed values.
+func GetOptionalEnum(cmd *cobra.Command, flagName, envKey string, enum *Enum, defaultValue string) (string, error) {
+	return GetEnum(cmd, flagName, envKey, enum, defaultValue, true)
+}
+
+// GetEnum returns values either command line flag or environment variable. The value is matched
+// case-insensitively against the allowed values (and their aliases) of the given Enum and the
+// matching allowed value is returned.
+func GetEnum(cmd *cobra.Command, flagName, envKey string, enum *Enum, defaultValue string,
+	isOptional bool) (string, error) {
+	str, err := GetUserSetVarFromString(cmd, flagName, envKey, isOptional)
+	if err != nil {
+		return "", err
+	}
+
+	if str == "" {
+		return defaultValue, nil
+	}
+
+	value, ok := enum.Match(str)
+	if !ok {
+		return "", fmt.Errorf("invalid value for %s [%s]: allowed values are %s", flagName, str, enum)
+	}
+
+	return value, nil
+}
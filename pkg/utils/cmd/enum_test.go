@@ -0,0 +1,178 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const (
+	dbTypeFlagName = "database-type"
+	dbTypeEnvKey   = "TEST_DATABASE_TYPE"
+)
+
+func TestEnum(t *testing.T) {
+	enum := cmd.NewEnum("mem", "MongoDB", "CouchDB").WithAlias("mongo", "MongoDB")
+
+	require.Equal(t, []string{"mem", "MongoDB", "CouchDB"}, enum.Values())
+	require.Equal(t, "mem, MongoDB, CouchDB", enum.String())
+	require.Equal(t, "Database type. Allowed values: mem, MongoDB, CouchDB.", enum.Usage("Database type."))
+
+	v, ok := enum.Match("MONGODB")
+	require.True(t, ok)
+	require.Equal(t, "MongoDB", v)
+
+	v, ok = enum.Match("Mongo")
+	require.True(t, ok)
+	require.Equal(t, "MongoDB", v)
+
+	v, ok = cmd.NewEnum("mem", "MongoDB").WithAlias("mongo", "mongodb").Match("mongo")
+	require.True(t, ok)
+	require.Equal(t, "MongoDB", v)
+
+	_, ok = cmd.NewEnum("mem").WithAlias("mongo", "mongodb").Match("mongo")
+	require.False(t, ok)
+
+	_, ok = enum.Match("postgres")
+	require.False(t, ok)
+
+	matches, directive := enum.CompletionFunc()(nil, nil, "m")
+	require.Equal(t, []string{"mem", "MongoDB"}, matches)
+	require.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+}
+
+func TestGetEnum(t *testing.T) {
+	enum := cmd.NewEnum("mem", "mongodb").WithAlias("mongo", "mongodb")
+
+	command := &cobra.Command{
+		Use: "start",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+
+	require.NoError(t, cmd.AddEnumFlag(command, dbTypeFlagName, "", "Database type.", enum))
+	require.Contains(t, command.Flags().Lookup(dbTypeFlagName).Usage, "Allowed values: mem, mongodb.")
+
+	t.Run("default", func(t *testing.T) {
+		v, err := cmd.GetEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem", true)
+		require.NoError(t, err)
+		require.Equal(t, "mem", v)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		_, err := cmd.GetEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem", false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Neither database-type (command line flag) nor TEST_DATABASE_TYPE")
+	})
+
+	t.Run("env var", func(t *testing.T) {
+		t.Setenv(dbTypeEnvKey, "MONGO")
+
+		v, err := cmd.GetEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem", true)
+		require.NoError(t, err)
+		require.Equal(t, "mongodb", v)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv(dbTypeEnvKey, "couchdb")
+
+		_, err := cmd.GetEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem", true)
+		require.EqualError(t, err, "invalid value for database-type [couchdb]: allowed values are mem, mongodb")
+
+		_, err = cmd.GetOptionalEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem")
+		require.EqualError(t, err, "invalid value for database-type [couchdb]: allowed values are mem, mongodb")
+	})
+
+	t.Run("optional not set", func(t *testing.T) {
+		v, err := cmd.GetOptionalEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem")
+		require.NoError(t, err)
+		require.Equal(t, "mem", v)
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		command.SetArgs([]string{"--" + dbTypeFlagName, "MongoDB"})
+		require.NoError(t, command.Execute())
+
+		v, err := cmd.GetOptionalEnum(command, dbTypeFlagName, dbTypeEnvKey, enum, "mem")
+		require.NoError(t, err)
+		require.Equal(t, "mongodb", v)
+	})
+
+	t.Run("completion", func(t *testing.T) {
+		root := &cobra.Command{Use: "root"}
+		start := &cobra.Command{
+			Use: "start",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return nil
+			},
+		}
+
+		root.AddCommand(start)
+
+		require.NoError(t, cmd.AddEnumFlag(start, dbTypeFlagName, "", "Database type.", enum))
+
+		out := &bytes.Buffer{}
+		root.SetOut(out)
+		root.SetArgs([]string{cobra.ShellCompRequestCmd, "start", "--" + dbTypeFlagName, "m"})
+		require.NoError(t, root.Execute())
+		require.Contains(t, out.String(), "mem\nmongodb\n")
+	})
+}
+
+func TestRegistry_ResolveEnum(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(&cmd.Parameter{
+		FlagName:    dbTypeFlagName,
+		EnvKey:      dbTypeEnvKey,
+		Enum:        []string{"mem", "mongodb"},
+		EnumAliases: map[string]string{"mongo": "mongodb"},
+		Default:     "mem",
+	}))
+
+	command := &cobra.Command{Use: "start"}
+	r.AddFlags(command)
+
+	require.Contains(t, command.Flags().Lookup(dbTypeFlagName).Usage, "Allowed values: mem, mongodb.")
+
+	t.Setenv(dbTypeEnvKey, "Mongo")
+
+	cfg, err := r.Resolve(command)
+	require.NoError(t, err)
+	require.Equal(t, "mongodb", cfg.String(dbTypeFlagName))
+
+	err = cmd.NewRegistry().Register(&cmd.Parameter{
+		FlagName:    dbTypeFlagName,
+		EnvKey:      dbTypeEnvKey,
+		Enum:        []string{"mem"},
+		EnumAliases: map[string]string{"mongo": "mongodb"},
+	})
+	require.EqualError(t, err, "alias mongo of parameter database-type refers to unknown value mongodb")
+
+	err = cmd.NewRegistry().Register(&cmd.Parameter{
+		FlagName: dbTypeFlagName,
+		EnvKey:   dbTypeEnvKey,
+		Enum:     []string{"mem", "mongodb"},
+		Default:  "couchdb",
+	})
+	require.EqualError(t, err, "default value [couchdb] of parameter database-type isn't one of the allowed "+
+		"values mem, mongodb")
+
+	err = cmd.NewRegistry().Register(&cmd.Parameter{
+		FlagName: "workers",
+		EnvKey:   "TEST_WORKERS",
+		Type:     cmd.IntType,
+		Enum:     []string{"1", "2"},
+	})
+	require.EqualError(t, err, "allowed values are only supported for string parameters: workers")
+}
@@ -0,0 +1,233 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// ExecValuePrefix is the prefix of a value which is obtained by running a command, the secret provider
+	// (e.g. "exec:/usr/bin/get-secret db-password"). The standard output of the command is the value.
+	ExecValuePrefix = "exec:"
+	// ExecTimeoutEnvKey is the environment variable which overrides the timeout of secret provider commands.
+	ExecTimeoutEnvKey = "CONFIG_EXEC_TIMEOUT"
+	// DefaultExecTimeout is the default timeout of secret provider commands.
+	DefaultExecTimeout = 10 * time.Second
+)
+
+// execCache caches the output of secret provider commands by command line during a single resolution so that
+// each command runs once. The lock is only held to look up the entry of a command, not while the command runs.
+type execCache struct {
+	mutex   sync.Mutex
+	entries map[string]*execCacheEntry
+}
+
+type execCacheEntry struct {
+	once  sync.Once
+	value string
+	err   error
+}
+
+func newExecCache() *execCache {
+	return &execCache{entries: make(map[string]*execCacheEntry)}
+}
+
+// run runs the given secret provider command line unless it already ran with this cache. A nil cache doesn't
+// cache anything.
+func (c *execCache) run(ctx context.Context, commandLine string) (string, error) {
+	if c == nil {
+		return RunSecretProvider(ctx, commandLine)
+	}
+
+	c.mutex.Lock()
+
+	e, ok := c.entries[commandLine]
+	if !ok {
+		e = &execCacheEntry{}
+		c.entries[commandLine] = e
+	}
+
+	c.mutex.Unlock()
+
+	e.once.Do(func() {
+		e.value, e.err = RunSecretProvider(ctx, commandLine)
+	})
+
+	return e.value, e.err
+}
+
+// IsExecValue returns true if the given value is obtained by running a secret provider command.
+func IsExecValue(value string) bool {
+	return strings.HasPrefix(value, ExecValuePrefix)
+}
+
+// RunSecretProvider runs the given secret provider command line and returns its standard output without
+// trailing line breaks. The command line is split into arguments as by a shell (quotes and backslash escapes
+// are supported) but isn't run by a shell. The command is killed if it doesn't complete within the timeout
+// (DefaultExecTimeout unless overridden with CONFIG_EXEC_TIMEOUT). The output isn't cached: Registry.Resolve
+// runs each command once per resolution. The returned error includes the standard error of the command.
+func RunSecretProvider(ctx context.Context, commandLine string) (string, error) {
+	args, err := splitCommandLine(commandLine)
+	if err != nil {
+		return "", err
+	}
+
+	if len(args) == 0 {
+		return "", errors.New("secret provider command is empty")
+	}
+
+	timeout, err := execTimeout()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+
+	//nolint:gosec // the command is configured by the operator
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Stdout = &limitedWriter{w: &stdout, remaining: MaxValueFileSize}
+	c.Stderr = &limitedWriter{w: &stderr, remaining: MaxValueFileSize}
+
+	if err := c.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			err = fmt.Errorf("timed out after %s", timeout)
+		}
+
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("run secret provider %s: %w: %s", args[0], err, msg)
+		}
+
+		return "", fmt.Errorf("run secret provider %s: %w", args[0], err)
+	}
+
+	if stdout.Len() > MaxValueFileSize {
+		return "", fmt.Errorf("run secret provider %s: output size exceeds the maximum of %d bytes",
+			args[0], MaxValueFileSize)
+	}
+
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
+
+func execTimeout() (time.Duration, error) {
+	value := os.Getenv(ExecTimeoutEnvKey)
+	if value == "" {
+		return DefaultExecTimeout, nil
+	}
+
+	timeout, err := ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("invalid value for %s [%s]: expecting a positive duration", ExecTimeoutEnvKey, value)
+	}
+
+	return timeout, nil
+}
+
+// execValue runs the secret provider of an "exec:" value of the command line flag or environment variable
+// with the given name, using the given cache.
+func execValue(cmd *cobra.Command, cache *execCache, name, value string) (string, error) {
+	ctx := context.Background()
+	if cmd != nil && cmd.Context() != nil {
+		ctx = cmd.Context()
+	}
+
+	result, err := cache.run(ctx, strings.TrimPrefix(value, ExecValuePrefix))
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+
+	return result, nil
+}
+
+// splitCommandLine splits the given command line into arguments separated by white space. Single quotes
+// preserve the enclosed characters, and a backslash escapes the next character outside single quotes.
+func splitCommandLine(s string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+		quote   rune
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inArg = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inArg = r, true
+		case unicode.IsSpace(r):
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+
+			inArg = true
+		}
+	}
+
+	if quote != 0 || escaped {
+		return nil, errors.New("invalid secret provider command: unterminated quote or escape")
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+// limitedWriter writes up to remaining bytes to w and then discards the rest while still counting one extra
+// byte so that the caller can detect that the limit was exceeded.
+type limitedWriter struct {
+	w         *bytes.Buffer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	n := len(p)
+
+	if l.remaining < 0 {
+		return n, nil
+	}
+
+	if len(p) > l.remaining {
+		p = p[:l.remaining+1]
+	}
+
+	l.w.Write(p)
+	l.remaining -= len(p)
+
+	return n, nil
+}
@@ -0,0 +1,242 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRunSecretProvider(t *testing.T) {
+	script := writeSecretProvider(t)
+
+	t.Run("success", func(t *testing.T) {
+		value, err := cmd.RunSecretProvider(context.Background(), script+" db-password")
+		require.NoError(t, err)
+		require.Equal(t, "secret-db-password", value)
+	})
+
+	t.Run("quoted arguments", func(t *testing.T) {
+		value, err := cmd.RunSecretProvider(context.Background(), script+` "db password" 'it'\''s'`)
+		require.NoError(t, err)
+		require.Equal(t, "secret-db password", value)
+	})
+
+	t.Run("not cached", func(t *testing.T) {
+		countFile := filepath.Join(t.TempDir(), "count")
+		t.Setenv("TEST_COUNT_FILE", countFile)
+
+		for i := 0; i < 2; i++ {
+			value, err := cmd.RunSecretProvider(context.Background(), script+" cached")
+			require.NoError(t, err)
+			require.Equal(t, "secret-cached", value)
+		}
+
+		count, err := os.ReadFile(countFile)
+		require.NoError(t, err)
+		require.Equal(t, "xx", string(count))
+	})
+
+	t.Run("failure includes stderr", func(t *testing.T) {
+		_, err := cmd.RunSecretProvider(context.Background(), script+" fail")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "run secret provider "+script+": exit status 3: secret fail not found")
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		t.Setenv(cmd.ExecTimeoutEnvKey, "100ms")
+
+		_, err := cmd.RunSecretProvider(context.Background(), script+" sleep")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "timed out after 100ms")
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		t.Setenv(cmd.ExecTimeoutEnvKey, "-1s")
+
+		_, err := cmd.RunSecretProvider(context.Background(), script+" other")
+		require.EqualError(t, err, "invalid value for CONFIG_EXEC_TIMEOUT [-1s]: expecting a positive duration")
+	})
+
+	t.Run("invalid command", func(t *testing.T) {
+		_, err := cmd.RunSecretProvider(context.Background(), " ")
+		require.EqualError(t, err, "secret provider command is empty")
+
+		_, err = cmd.RunSecretProvider(context.Background(), script+` "unterminated`)
+		require.EqualError(t, err, "invalid secret provider command: unterminated quote or escape")
+
+		_, err = cmd.RunSecretProvider(context.Background(), filepath.Join(t.TempDir(), "missing"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "run secret provider")
+	})
+}
+
+func TestExecValue(t *testing.T) {
+	script := writeSecretProvider(t)
+
+	r := cmd.NewRegistry()
+	require.NoError(t, r.Register(&cmd.Parameter{
+		FlagName:  flagName,
+		EnvKey:    envKey,
+		AllowExec: true,
+		AllowFile: true,
+	}))
+
+	t.Run("environment variable", func(t *testing.T) {
+		t.Setenv(envKey, cmd.ExecValuePrefix+script+" api-key")
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.NoError(t, err)
+		require.Equal(t, "secret-api-key", cfg.String(flagName))
+	})
+
+	t.Run("command line flag", func(t *testing.T) {
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--"+flagName, "exec:"+script+" token"))
+		require.NoError(t, err)
+		require.Equal(t, "secret-token", cfg.String(flagName))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		t.Setenv(envKey, cmd.ExecValuePrefix+script+" fail")
+
+		_, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), envKey+": run secret provider")
+		require.Contains(t, err.Error(), "secret fail not found")
+	})
+
+	t.Run("file containing exec value", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "value")
+		require.NoError(t, os.WriteFile(file, []byte("exec:"+script+" from-file\n"), 0o600))
+
+		t.Setenv(envKey, "@"+file)
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.NoError(t, err)
+		require.Equal(t, "exec:"+script+" from-file", cfg.String(flagName))
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		t.Setenv(envKey, cmd.ExecValuePrefix+script+" api-key")
+
+		value, err := cmd.GetString(newCommand(t, hostURLFlags), flagName, envKey, false)
+		require.NoError(t, err)
+		require.Equal(t, cmd.ExecValuePrefix+script+" api-key", value)
+
+		value, err = cmd.GetUserSetVarFromString(newCommand(t, hostURLFlags, "--"+flagName, "exec:"+script+" token"),
+			flagName, envKey, false)
+		require.NoError(t, err)
+		require.Equal(t, "exec:"+script+" token", value)
+	})
+
+	t.Run("unsupported type", func(t *testing.T) {
+		err := cmd.NewRegistry().Register(&cmd.Parameter{FlagName: "x", EnvKey: "X", Type: cmd.IntType,
+			AllowExec: true})
+		require.EqualError(t, err, "a secret provider is only supported for string parameters: x")
+	})
+
+	require.True(t, cmd.IsExecValue("exec:/bin/true"))
+	require.False(t, cmd.IsExecValue("/bin/true"))
+}
+
+func TestParameterExec(t *testing.T) {
+	script := writeSecretProvider(t)
+
+	r := cmd.NewRegistry()
+	require.NoError(t, r.Register(&cmd.Parameter{
+		FlagName: "db-password",
+		EnvKey:   "TEST_DB_PASSWORD",
+		Required: true,
+		Secret:   true,
+		Exec:     script + " db-password",
+	}))
+
+	t.Run("provider", func(t *testing.T) {
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.NoError(t, err)
+		require.Equal(t, "secret-db-password", cfg.String("db-password"))
+
+		v, ok := cfg.Get("db-password")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceExec, v.Source)
+		require.Equal(t, cmd.RedactedValue, v.String())
+	})
+
+	t.Run("flag takes precedence", func(t *testing.T) {
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--db-password", "from-flag"))
+		require.NoError(t, err)
+		require.Equal(t, "from-flag", cfg.String("db-password"))
+	})
+
+	t.Run("runs once per resolution", func(t *testing.T) {
+		countFile := filepath.Join(t.TempDir(), "count")
+		t.Setenv("TEST_COUNT_FILE", countFile)
+
+		shared := cmd.NewRegistry()
+		require.NoError(t, shared.Register(
+			&cmd.Parameter{FlagName: "db-password", EnvKey: "TEST_DB_PASSWORD", Exec: script + " shared"},
+			&cmd.Parameter{FlagName: "db-replica-password", EnvKey: "TEST_DB_REPLICA_PASSWORD", Exec: script + " shared"},
+		))
+
+		for i := 0; i < 2; i++ {
+			cfg, err := shared.Resolve(&cobra.Command{})
+			require.NoError(t, err)
+			require.Equal(t, "secret-shared", cfg.String("db-password"))
+			require.Equal(t, "secret-shared", cfg.String("db-replica-password"))
+		}
+
+		count, err := os.ReadFile(countFile)
+		require.NoError(t, err)
+		require.Equal(t, "xx", string(count))
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		failing := cmd.NewRegistry()
+		require.NoError(t, failing.Register(&cmd.Parameter{
+			FlagName: "api-key", EnvKey: "TEST_API_KEY", Exec: script + " fail",
+		}))
+
+		_, err := failing.Resolve(&cobra.Command{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "api-key: run secret provider")
+	})
+}
+
+// writeSecretProvider writes a secret provider script which prints "secret-<name>", fails for "fail", sleeps
+// for "sleep" and records its invocations in $TEST_COUNT_FILE (if set).
+func writeSecretProvider(t *testing.T) string {
+	t.Helper()
+
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		`if [ -n "$TEST_COUNT_FILE" ]; then printf x >> "$TEST_COUNT_FILE"; fi`,
+		`case "$1" in`,
+		`fail) echo "secret $1 not found" >&2; exit 3 ;;`,
+		`sleep) exec sleep 5 ;;`,
+		`esac`,
+		`echo "secret-$1"`,
+	}, "\n") + "\n"
+
+	path := filepath.Join(t.TempDir(), "get-secret")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0o700)) //nolint:gosec // the script must be executable
+
+	return path
+}
+
+// hostURLFlags adds the host URL flag.
+func hostURLFlags(command *cobra.Command) error {
+	command.Flags().String(flagName, "", "")
+
+	return nil
+}
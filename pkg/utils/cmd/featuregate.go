@@ -0,0 +1,236 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/trustbloc/cmdutil-go/internal/logfields"
+)
+
+// Feature is the name of a feature gate.
+type Feature string
+
+// FeatureStage is the maturity stage of a feature.
+type FeatureStage string
+
+// Feature stages.
+const (
+	// StageAlpha features are experimental and disabled by default.
+	StageAlpha FeatureStage = "alpha"
+	// StageBeta features are well tested and usually enabled by default.
+	StageBeta FeatureStage = "beta"
+	// StageGA features are generally available. A GA feature is locked to its default value.
+	StageGA FeatureStage = "ga"
+	// StageDeprecated features are going to be removed. A warning is logged when a deprecated feature is set.
+	StageDeprecated FeatureStage = "deprecated"
+)
+
+// FeatureSpec describes a feature gate.
+type FeatureSpec struct {
+	// Stage is the maturity stage of the feature.
+	Stage FeatureStage
+	// Default indicates whether the feature is enabled when its gate isn't set.
+	Default bool
+	// Description is a human readable description of the feature.
+	Description string
+}
+
+// FeatureGates holds the feature gates declared by an application and whether each feature is enabled.
+type FeatureGates struct {
+	mutex   sync.RWMutex
+	specs   map[Feature]FeatureSpec
+	enabled map[Feature]bool
+}
+
+// NewFeatureGates returns a new, empty set of feature gates.
+func NewFeatureGates() *FeatureGates {
+	return &FeatureGates{
+		specs:   make(map[Feature]FeatureSpec),
+		enabled: make(map[Feature]bool),
+	}
+}
+
+// Add declares the given features. An error is returned if a feature is already declared or
+// if its stage is invalid.
+func (g *FeatureGates) Add(features map[Feature]FeatureSpec) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for name, spec := range features {
+		if name == "" {
+			return errors.New("feature name is empty")
+		}
+
+		switch spec.Stage {
+		case StageAlpha, StageBeta, StageGA, StageDeprecated:
+		default:
+			return fmt.Errorf("unsupported stage [%s] for feature %s", spec.Stage, name)
+		}
+
+		if _, ok := g.specs[name]; ok {
+			return fmt.Errorf("feature %s is already declared", name)
+		}
+	}
+
+	for name, spec := range features {
+		g.specs[name] = spec
+	}
+
+	return nil
+}
+
+// Set sets the feature gates from comma-separated name=bool pairs (e.g. "A=true,B=false").
+func (g *FeatureGates) Set(value string) error {
+	m, err := ParseStringMap(value, DuplicateKeyError)
+	if err != nil {
+		return err
+	}
+
+	gates := make(map[Feature]bool, len(m))
+
+	for name, v := range m {
+		enabled, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return fmt.Errorf("invalid value of feature gate %s [%s]: %w", name, v, err)
+		}
+
+		gates[Feature(name)] = enabled
+	}
+
+	return g.SetFromMap(gates)
+}
+
+// SetFromMap sets the given feature gates. An error is returned if a feature isn't declared or if a GA
+// feature is set to a value other than its default. No gate is set if an error is returned.
+func (g *FeatureGates) SetFromMap(gates map[Feature]bool) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	for _, name := range sortedFeatures(gates) {
+		spec, ok := g.specs[name]
+		if !ok {
+			return fmt.Errorf("unrecognized feature gate: %s", name)
+		}
+
+		if spec.Stage == StageGA && gates[name] != spec.Default {
+			return fmt.Errorf("cannot set feature gate %s to %t: feature is GA and locked to %t",
+				name, gates[name], spec.Default)
+		}
+	}
+
+	for _, name := range sortedFeatures(gates) {
+		if g.specs[name].Stage == StageDeprecated {
+			logger.Warn("Deprecated feature gate is set", logfields.WithFeature(string(name)))
+		}
+
+		g.enabled[name] = gates[name]
+	}
+
+	return nil
+}
+
+// Enabled returns true if the given feature is enabled. False is returned for an undeclared feature.
+func (g *FeatureGates) Enabled(name Feature) bool {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	if enabled, ok := g.enabled[name]; ok {
+		return enabled
+	}
+
+	return g.specs[name].Default
+}
+
+// Features returns the declared features sorted by name.
+func (g *FeatureGates) Features() []Feature {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	return sortedFeatures(g.specs)
+}
+
+// Spec returns the specification of the given feature.
+func (g *FeatureGates) Spec(name Feature) (FeatureSpec, bool) {
+	g.mutex.RLock()
+	defer g.mutex.RUnlock()
+
+	spec, ok := g.specs[name]
+
+	return spec, ok
+}
+
+// Usage returns one line per declared feature describing its stage and default
+// (e.g. "MyFeature=true|false (ALPHA - default=false)"), suitable for the help of the feature gates flag.
+func (g *FeatureGates) Usage() []string {
+	var lines []string
+
+	for _, name := range g.Features() {
+		spec, _ := g.Spec(name)
+
+		line := fmt.Sprintf("%s=true|false (%s - default=%t)", name, strings.ToUpper(string(spec.Stage)),
+			spec.Default)
+
+		if spec.Description != "" {
+			line += " " + spec.Description
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// AddFlag adds the feature gates flag to the given command. The usage of the flag lists the declared features.
+func (g *FeatureGates) AddFlag(cmd *cobra.Command, flagName, envKey string) {
+	usage := "A set of key=value pairs that enable or disable features (e.g. A=true,B=false)."
+
+	if lines := g.Usage(); len(lines) > 0 {
+		usage += " Options are:\n" + strings.Join(lines, "\n") + "\n"
+	}
+
+	cmd.Flags().String(flagName, "", usage+
+		" Alternatively, this can be set with the following environment variable: "+envKey)
+}
+
+// Resolve sets the feature gates from either command line flag or environment variable.
+// The feature gates are optional. The value is taken literally: it isn't loaded from a file ("@path"),
+// obtained from a secret provider ("exec:") or decrypted.
+func (g *FeatureGates) Resolve(cmd *cobra.Command, flagName, envKey string) error {
+	value, err := getString(cmd, flagName, envKey, true, nil)
+	if err != nil {
+		return err
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	if err := g.Set(value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", flagName, err)
+	}
+
+	return nil
+}
+
+func sortedFeatures[T any](m map[Feature]T) []Feature {
+	names := make([]Feature, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+
+	return names
+}
@@ -0,0 +1,193 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/logutil-go/pkg/log"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const (
+	featureGatesFlagName = "feature-gates"
+	featureGatesEnvKey   = "TEST_FEATURE_GATES"
+
+	featureAlpha      cmd.Feature = "AlphaFeature"
+	featureBeta       cmd.Feature = "BetaFeature"
+	featureGA         cmd.Feature = "GAFeature"
+	featureDeprecated cmd.Feature = "DeprecatedFeature"
+)
+
+func TestFeatureGates(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		require.False(t, g.Enabled(featureAlpha))
+		require.True(t, g.Enabled(featureBeta))
+		require.True(t, g.Enabled(featureGA))
+		require.True(t, g.Enabled(featureDeprecated))
+		require.False(t, g.Enabled("Unknown"))
+
+		require.Equal(t, []cmd.Feature{featureAlpha, featureBeta, featureDeprecated, featureGA}, g.Features())
+
+		spec, ok := g.Spec(featureAlpha)
+		require.True(t, ok)
+		require.Equal(t, cmd.StageAlpha, spec.Stage)
+	})
+
+	t.Run("set", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		require.NoError(t, g.Set("AlphaFeature=true, BetaFeature=false,GAFeature=true,DeprecatedFeature=false"))
+		require.True(t, g.Enabled(featureAlpha))
+		require.False(t, g.Enabled(featureBeta))
+		require.True(t, g.Enabled(featureGA))
+		require.False(t, g.Enabled(featureDeprecated))
+
+		require.NoError(t, g.SetFromMap(map[cmd.Feature]bool{featureAlpha: false}))
+		require.False(t, g.Enabled(featureAlpha))
+	})
+
+	t.Run("set errors", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		err := g.Set("AlphaFeature=true,Unknown=true")
+		require.EqualError(t, err, "unrecognized feature gate: Unknown")
+		require.False(t, g.Enabled(featureAlpha))
+
+		err = g.Set("GAFeature=false")
+		require.EqualError(t, err, "cannot set feature gate GAFeature to false: feature is GA and locked to true")
+
+		err = g.Set("AlphaFeature=yes")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value of feature gate AlphaFeature [yes]")
+
+		err = g.Set("AlphaFeature")
+		require.EqualError(t, err, "invalid entry [AlphaFeature] for value: expecting key=value")
+	})
+
+	t.Run("add errors", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		err := g.Add(map[cmd.Feature]cmd.FeatureSpec{featureAlpha: {Stage: cmd.StageAlpha}})
+		require.EqualError(t, err, "feature AlphaFeature is already declared")
+
+		err = g.Add(map[cmd.Feature]cmd.FeatureSpec{"Other": {Stage: "experimental"}})
+		require.EqualError(t, err, "unsupported stage [experimental] for feature Other")
+
+		err = g.Add(map[cmd.Feature]cmd.FeatureSpec{"": {Stage: cmd.StageAlpha}})
+		require.EqualError(t, err, "feature name is empty")
+	})
+
+	t.Run("flag", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		command := &cobra.Command{Use: "start", Run: func(*cobra.Command, []string) {}}
+		g.AddFlag(command, featureGatesFlagName, featureGatesEnvKey)
+
+		usage := command.Flags().Lookup(featureGatesFlagName).Usage
+		require.Contains(t, usage, "AlphaFeature=true|false (ALPHA - default=false) Enables the alpha feature.\n")
+		require.Contains(t, usage, "GAFeature=true|false (GA - default=true)\n")
+		require.Contains(t, usage, "environment variable: TEST_FEATURE_GATES")
+
+		t.Setenv(featureGatesEnvKey, "BetaFeature=false")
+
+		command.SetArgs([]string{"--feature-gates", "AlphaFeature=true"})
+		require.NoError(t, command.Execute())
+
+		require.NoError(t, g.Resolve(command, featureGatesFlagName, featureGatesEnvKey))
+		require.True(t, g.Enabled(featureAlpha))
+		require.True(t, g.Enabled(featureBeta))
+	})
+
+	t.Run("env var", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		command := &cobra.Command{Use: "start"}
+		g.AddFlag(command, featureGatesFlagName, featureGatesEnvKey)
+
+		require.NoError(t, g.Resolve(command, featureGatesFlagName, featureGatesEnvKey))
+		require.True(t, g.Enabled(featureBeta))
+
+		t.Setenv(featureGatesEnvKey, "BetaFeature=false")
+
+		require.NoError(t, g.Resolve(command, featureGatesFlagName, featureGatesEnvKey))
+		require.False(t, g.Enabled(featureBeta))
+
+		t.Setenv(featureGatesEnvKey, "Unknown=false")
+
+		err := g.Resolve(command, featureGatesFlagName, featureGatesEnvKey)
+		require.EqualError(t, err, "invalid value for feature-gates: unrecognized feature gate: Unknown")
+	})
+
+	t.Run("no indirection", func(t *testing.T) {
+		g := newFeatureGates(t)
+
+		file := filepath.Join(t.TempDir(), "gates")
+		require.NoError(t, os.WriteFile(file, []byte("true"), 0o600))
+
+		command := &cobra.Command{Use: "start"}
+		g.AddFlag(command, featureGatesFlagName, featureGatesEnvKey)
+
+		t.Setenv(featureGatesEnvKey, "AlphaFeature=@"+file)
+
+		err := g.Resolve(command, featureGatesFlagName, featureGatesEnvKey)
+		require.EqualError(t, err, "invalid value for feature-gates: invalid value of feature gate AlphaFeature "+
+			"[@"+file+`]: strconv.ParseBool: parsing "@`+file+`": invalid syntax`)
+
+		t.Setenv(featureGatesEnvKey, "@"+file)
+
+		err = g.Resolve(command, featureGatesFlagName, featureGatesEnvKey)
+		require.EqualError(t, err, "invalid value for feature-gates: invalid entry [@"+file+
+			"] for value: expecting key=value")
+
+		t.Setenv(featureGatesEnvKey, "AlphaFeature="+cmd.ExecValuePrefix+"echo true")
+
+		err = g.Resolve(command, featureGatesFlagName, featureGatesEnvKey)
+		require.Error(t, err)
+		require.False(t, g.Enabled(featureAlpha))
+	})
+}
+
+func TestLogFeatureGates(t *testing.T) {
+	g := newFeatureGates(t)
+	require.NoError(t, g.Set("AlphaFeature=true"))
+
+	out := &bytes.Buffer{}
+
+	cmd.LogFeatureGates(log.New("test", log.WithStdOut(&syncWriter{out}), log.WithEncoding(log.JSON)), g)
+
+	entries := readLogEntries(t, out)
+	require.Len(t, entries, 4)
+	require.Equal(t, "Feature gate", entries[0]["msg"])
+	require.Equal(t, "AlphaFeature", entries[0]["feature"])
+	require.Equal(t, "alpha", entries[0]["stage"])
+	require.Equal(t, true, entries[0]["enabled"])
+	require.Equal(t, "DeprecatedFeature", entries[2]["feature"])
+	require.Equal(t, "deprecated", entries[2]["stage"])
+}
+
+func newFeatureGates(t *testing.T) *cmd.FeatureGates {
+	t.Helper()
+
+	g := cmd.NewFeatureGates()
+
+	require.NoError(t, g.Add(map[cmd.Feature]cmd.FeatureSpec{
+		featureAlpha:      {Stage: cmd.StageAlpha, Description: "Enables the alpha feature."},
+		featureBeta:       {Stage: cmd.StageBeta, Default: true},
+		featureGA:         {Stage: cmd.StageGA, Default: true},
+		featureDeprecated: {Stage: cmd.StageDeprecated, Default: true},
+	}))
+
+	return g
+}
@@ -0,0 +1,128 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// OptionGroup is a group of related parameters (e.g. the TLS parameters of a listener) which resolves into
+// a typed parameters struct T. A group is usually instantiated with a flag and environment variable prefix
+// (e.g. "admin-tls-" and "ADMIN_TLS_") so that several instances may be added to the same command.
+type OptionGroup[T any] interface {
+	// Parameters returns the parameters of the group.
+	Parameters() []*Parameter
+	// AddFlags adds the command line flags of the group to the given command.
+	AddFlags(cmd *cobra.Command) error
+	// Resolve resolves the parameters of the group from the command line flags and environment variables
+	// of the given command.
+	Resolve(cmd *cobra.Command) (T, error)
+	// Validate validates the resolved parameters.
+	Validate(params T) error
+}
+
+// ResolveGroup resolves and validates the parameters of the given group.
+func ResolveGroup[T any](cmd *cobra.Command, group OptionGroup[T]) (T, error) {
+	params, err := group.Resolve(cmd)
+	if err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	if err := group.Validate(params); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return params, nil
+}
+
+// GroupPrefix is the prefix of the flag names and environment variables of an option group.
+type GroupPrefix struct {
+	// Flag is the prefix of the flag names (e.g. "admin-tls-").
+	Flag string
+	// Env is the prefix of the environment variables (e.g. "ADMIN_TLS_").
+	Env string
+}
+
+// FlagName returns the prefixed flag name of the given parameter name.
+func (p GroupPrefix) FlagName(name string) string {
+	return p.Flag + name
+}
+
+// EnvKey returns the prefixed environment variable of the given parameter name. The name is upper-cased
+// and dashes are replaced with underscores (e.g. "serve-key" becomes "SERVE_KEY").
+func (p GroupPrefix) EnvKey(name string) string {
+	return p.Env + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// parameter sets the prefixed flag name and environment variable of the given parameter name on the given
+// parameter and returns it.
+func (p GroupPrefix) parameter(name string, param *Parameter) *Parameter {
+	param.FlagName = p.FlagName(name)
+	param.EnvKey = p.EnvKey(name)
+
+	return param
+}
+
+// addGroupFlags adds a command line flag for each of the given parameters to the given flag set in the same way
+// as Registry.AddFlags. Parameters without a flag name (e.g. a field left empty in TLSFields) are skipped.
+func addGroupFlags(cmd *cobra.Command, flags *pflag.FlagSet, params []*Parameter) error {
+	r, err := newGroupRegistry(params)
+	if err != nil {
+		return err
+	}
+
+	r.addFlags(cmd, flags)
+
+	return nil
+}
+
+// resolveGroupConfig resolves the given parameters of an option group as Registry.Resolve.
+func resolveGroupConfig(cmd *cobra.Command, params []*Parameter) (*Config, error) {
+	r, err := newGroupRegistry(params)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.Resolve(cmd)
+}
+
+// appendValidationErrors appends the given error, or the errors it contains if it is a ValidationError,
+// to the given errors.
+func appendValidationErrors(errs []error, err error) []error {
+	var validationErr *ValidationError
+
+	if errors.As(err, &validationErr) {
+		return append(errs, validationErr.Errors...)
+	}
+
+	return append(errs, err)
+}
+
+// newGroupRegistry returns a registry of the given parameters of an option group. Parameters without a flag
+// name are skipped.
+func newGroupRegistry(params []*Parameter) (*Registry, error) {
+	r := NewRegistry()
+
+	for _, p := range params {
+		if p.FlagName == "" {
+			continue
+		}
+
+		if err := r.Register(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestGroupPrefix(t *testing.T) {
+	prefix := cmd.GroupPrefix{Flag: "admin-tls-", Env: "ADMIN_TLS_"}
+
+	require.Equal(t, "admin-tls-serve-key", prefix.FlagName("serve-key"))
+	require.Equal(t, "ADMIN_TLS_SERVE_KEY", prefix.EnvKey("serve-key"))
+
+	require.Equal(t, "key", cmd.GroupPrefix{}.FlagName("key"))
+	require.Equal(t, "KEY", cmd.GroupPrefix{}.EnvKey("key"))
+}
+
+func TestResolveGroup(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		params, err := cmd.ResolveGroup[string](&cobra.Command{}, &mockGroup{value: "value"})
+		require.NoError(t, err)
+		require.Equal(t, "value", params)
+	})
+
+	t.Run("resolve error", func(t *testing.T) {
+		params, err := cmd.ResolveGroup[string](&cobra.Command{}, &mockGroup{resolveErr: errors.New("resolve")})
+		require.EqualError(t, err, "resolve")
+		require.Empty(t, params)
+	})
+
+	t.Run("validate error", func(t *testing.T) {
+		params, err := cmd.ResolveGroup[string](&cobra.Command{},
+			&mockGroup{value: "value", validateErr: errors.New("validate")})
+		require.EqualError(t, err, "validate")
+		require.Empty(t, params)
+	})
+}
+
+type mockGroup struct {
+	value       string
+	resolveErr  error
+	validateErr error
+}
+
+func (g *mockGroup) Parameters() []*cmd.Parameter {
+	return nil
+}
+
+func (g *mockGroup) AddFlags(*cobra.Command) error {
+	return nil
+}
+
+func (g *mockGroup) Resolve(*cobra.Command) (string, error) {
+	return g.value, g.resolveErr
+}
+
+func (g *mockGroup) Validate(string) error {
+	return g.validateErr
+}
@@ -0,0 +1,383 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	tlsutil "github.com/trustbloc/cmdutil-go/pkg/utils/tls"
+)
+
+// Names of the HTTP server parameters. The flag names and environment variables of an HTTPServerGroup are
+// prefixed with the group prefix (e.g. "host-url" and "ORB_HOST_URL"). The TLS parameters are prefixed with
+// the group prefix followed by "tls-" and "TLS_" (e.g. "tls-certificate" and "ORB_TLS_CERTIFICATE").
+const (
+	HTTPHostURLName            = "host-url"
+	HTTPReadTimeoutName        = "read-timeout"
+	HTTPWriteTimeoutName       = "write-timeout"
+	HTTPIdleTimeoutName        = "idle-timeout"
+	HTTPReadHeaderTimeoutName  = "read-header-timeout"
+	HTTPMaxHeaderBytesName     = "max-header-bytes"
+	HTTPCORSAllowedOriginsName = "cors-allowed-origins"
+	HTTPCORSAllowedMethodsName = "cors-allowed-methods"
+	HTTPCORSAllowedHeadersName = "cors-allowed-headers"
+	HTTPTLSClientAuthName      = "tls-client-auth"
+)
+
+// Client certificate policies of an HTTP server (see tls.ClientAuthType).
+const (
+	ClientAuthNone             = "none"
+	ClientAuthRequest          = "request"
+	ClientAuthRequireAny       = "require-any"
+	ClientAuthVerifyIfGiven    = "verify-if-given"
+	ClientAuthRequireAndVerify = "require-and-verify"
+)
+
+var clientAuthTypes = map[string]tls.ClientAuthType{
+	ClientAuthNone:             tls.NoClientCert,
+	ClientAuthRequest:          tls.RequestClientCert,
+	ClientAuthRequireAny:       tls.RequireAnyClientCert,
+	ClientAuthVerifyIfGiven:    tls.VerifyClientCertIfGiven,
+	ClientAuthRequireAndVerify: tls.RequireAndVerifyClientCert,
+}
+
+// DefaultHTTPReadHeaderTimeout is the default timeout for reading the request headers.
+const DefaultHTTPReadHeaderTimeout = 10 * time.Second
+
+// CORSParameters contains the Cross-Origin Resource Sharing parameters of an HTTP server.
+type CORSParameters struct {
+	// AllowedOrigins are the allowed origins ("*" allows all origins). CORS is disabled if empty.
+	AllowedOrigins []string
+	// AllowedMethods are the allowed methods. Defaults to the simple methods (GET, HEAD and POST).
+	AllowedMethods []string
+	// AllowedHeaders are the allowed request headers.
+	AllowedHeaders []string
+}
+
+// HTTPServerParameters contains the HTTP server parameters resolved by an HTTPServerGroup.
+type HTTPServerParameters struct {
+	TLSParameters
+
+	// Address is the host:port on which the server listens.
+	Address string
+	// ReadTimeout is the maximum duration for reading the entire request. Zero means no timeout.
+	ReadTimeout time.Duration
+	// WriteTimeout is the maximum duration before timing out writes of the response. Zero means no timeout.
+	WriteTimeout time.Duration
+	// IdleTimeout is the maximum amount of time to wait for the next request when keep-alives are enabled.
+	IdleTimeout time.Duration
+	// ReadHeaderTimeout is the amount of time allowed to read the request headers.
+	ReadHeaderTimeout time.Duration
+	// MaxHeaderBytes is the maximum size of the request headers. Zero means http.DefaultMaxHeaderBytes.
+	MaxHeaderBytes int
+	// ClientAuth is the policy for client certificates, which are verified with the CA certs (and the system
+	// cert pool if enabled).
+	ClientAuth tls.ClientAuthType
+	// CORS contains the CORS parameters.
+	CORS CORSParameters
+}
+
+// TLSEnabled returns true if a serving certificate is configured.
+func (p *HTTPServerParameters) TLSEnabled() bool {
+	return p.ServeCertPath != ""
+}
+
+// TLSConfig returns the TLS configuration of the server: the serving certificate and key, the client certificate
+// policy and, if CA certs or the system cert pool are configured, the pool used to verify client certificates.
+// Nil is returned if TLS isn't enabled.
+func (p *HTTPServerParameters) TLSConfig() (*tls.Config, error) {
+	if !p.TLSEnabled() {
+		return nil, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(p.ServeCertPath, p.ServeKeyPath)
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair: %w", err)
+	}
+
+	cfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   p.ClientAuth,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if p.SystemCertPool || len(p.CACerts) > 0 {
+		cfg.ClientCAs, err = tlsutil.GetCertPool(p.SystemCertPool, p.CACerts)
+		if err != nil {
+			return nil, fmt.Errorf("load CA certs: %w", err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// NewServer returns an HTTP server configured with the parameters which serves the given handler. If CORS
+// origins are configured then the handler is wrapped with a CORS handler. If TLS is enabled then the server
+// must be started with ListenAndServeTLS("", "") since the certificate is part of its TLS configuration.
+func (p *HTTPServerParameters) NewServer(handler http.Handler) (*http.Server, error) {
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.CORS.AllowedOrigins) > 0 {
+		handler = p.CORS.Handler(handler)
+	}
+
+	return &http.Server{
+		Addr:              p.Address,
+		Handler:           handler,
+		TLSConfig:         tlsConfig,
+		ReadTimeout:       p.ReadTimeout,
+		WriteTimeout:      p.WriteTimeout,
+		IdleTimeout:       p.IdleTimeout,
+		ReadHeaderTimeout: p.ReadHeaderTimeout,
+		MaxHeaderBytes:    p.MaxHeaderBytes,
+	}, nil
+}
+
+// Handler returns an HTTP handler which adds the CORS headers to the responses of the given handler for
+// allowed origins and responds to preflight requests.
+func (c *CORSParameters) Handler(next http.Handler) http.Handler {
+	methods := c.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" || !c.originAllowed(origin) {
+			next.ServeHTTP(w, r)
+
+			return
+		}
+
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.Header().Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+
+			if len(c.AllowedHeaders) > 0 {
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+			}
+
+			w.WriteHeader(http.StatusNoContent)
+
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (c *CORSParameters) originAllowed(origin string) bool {
+	for _, o := range c.AllowedOrigins {
+		if o == "*" || strings.EqualFold(o, origin) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HTTPServerGroup is the option group of the parameters of an HTTP server, including its TLS parameters.
+type HTTPServerGroup struct {
+	prefix GroupPrefix
+	tls    *TLSGroup
+}
+
+var _ OptionGroup[*HTTPServerParameters] = (*HTTPServerGroup)(nil)
+
+// NewHTTPServerGroup returns an HTTP server option group with the given prefix (e.g. "admin-" and "ORB_ADMIN_").
+// The TLS options are passed to GetTLS.
+func NewHTTPServerGroup(prefix GroupPrefix, tlsOpts ...TLSOption) *HTTPServerGroup {
+	return &HTTPServerGroup{
+		prefix: prefix,
+		tls:    NewTLSGroup(GroupPrefix{Flag: prefix.Flag + "tls-", Env: prefix.Env + "TLS_"}, tlsOpts...),
+	}
+}
+
+// TLS returns the TLS option group of the server.
+func (g *HTTPServerGroup) TLS() *TLSGroup {
+	return g.tls
+}
+
+// Parameters returns the HTTP server parameters followed by the TLS parameters.
+func (g *HTTPServerGroup) Parameters() []*Parameter {
+	return append(g.serverParameters(), g.tls.Parameters()...)
+}
+
+func (g *HTTPServerGroup) serverParameters() []*Parameter {
+	minimum := 0.0
+
+	timeout := func(name, description string, defaultValue time.Duration) *Parameter {
+		return g.prefix.parameter(name, &Parameter{
+			Description:    description,
+			Type:           DurationType,
+			Default:        defaultValue,
+			DurationBounds: &DurationBounds{},
+		})
+	}
+
+	return []*Parameter{
+		g.prefix.parameter(HTTPHostURLName, &Parameter{
+			Description: "The address (host:port) or URL (e.g. https://0.0.0.0:8443) on which the server listens.",
+			Required:    true,
+		}),
+		timeout(HTTPReadTimeoutName, "The maximum duration for reading the entire request. "+
+			"Zero means no timeout.", 0),
+		timeout(HTTPWriteTimeoutName, "The maximum duration before timing out writes of the response. "+
+			"Zero means no timeout.", 0),
+		timeout(HTTPIdleTimeoutName, "The maximum amount of time to wait for the next request when "+
+			"keep-alives are enabled.", 0),
+		timeout(HTTPReadHeaderTimeoutName, "The amount of time allowed to read the request headers.",
+			DefaultHTTPReadHeaderTimeout),
+		g.prefix.parameter(HTTPMaxHeaderBytesName, &Parameter{
+			Description: "The maximum size in bytes of the request headers. Zero means 1 MB.",
+			Type:        IntType,
+			Minimum:     &minimum,
+		}),
+		g.prefix.parameter(HTTPCORSAllowedOriginsName, &Parameter{
+			Description: "The origins allowed to make cross-origin requests (\"*\" allows all origins).",
+			Type:        StringArrayType,
+		}),
+		g.prefix.parameter(HTTPCORSAllowedMethodsName, &Parameter{
+			Description: "The methods allowed for cross-origin requests. Defaults to GET, HEAD and POST.",
+			Type:        StringArrayType,
+		}),
+		g.prefix.parameter(HTTPCORSAllowedHeadersName, &Parameter{
+			Description: "The headers allowed in cross-origin requests.",
+			Type:        StringArrayType,
+		}),
+		g.prefix.parameter(HTTPTLSClientAuthName, &Parameter{
+			Description: "The policy for TLS client certificates. Client certificates are only requested or " +
+				"verified if the policy is set explicitly.",
+			Default: ClientAuthNone,
+			Enum: []string{
+				ClientAuthNone, ClientAuthRequest, ClientAuthRequireAny, ClientAuthVerifyIfGiven,
+				ClientAuthRequireAndVerify,
+			},
+		}),
+	}
+}
+
+// AddFlags adds the HTTP server and TLS flags to the given command.
+func (g *HTTPServerGroup) AddFlags(cmd *cobra.Command) error {
+	return addGroupFlags(cmd, cmd.Flags(), g.Parameters())
+}
+
+// Resolve resolves the HTTP server and TLS parameters. All errors are returned together in a ValidationError.
+func (g *HTTPServerGroup) Resolve(cmd *cobra.Command) (*HTTPServerParameters, error) {
+	var errs []error
+
+	tlsParams, err := g.tls.Resolve(cmd)
+	if err != nil {
+		errs = appendValidationErrors(errs, err)
+	}
+
+	cfg, err := resolveGroupConfig(cmd, g.serverParameters())
+	if err != nil {
+		return nil, &ValidationError{Errors: appendValidationErrors(errs, err)}
+	}
+
+	hostURLName := g.prefix.FlagName(HTTPHostURLName)
+
+	address, err := parseHostURL(cfg.String(hostURLName))
+	if err != nil {
+		errs = append(errs, fmt.Errorf("invalid value for %s: %w", hostURLName, err))
+	}
+
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	methods := cfg.StringArray(g.prefix.FlagName(HTTPCORSAllowedMethodsName))
+	for i, m := range methods {
+		methods[i] = strings.ToUpper(strings.TrimSpace(m))
+	}
+
+	return &HTTPServerParameters{
+		TLSParameters:     *tlsParams,
+		Address:           address,
+		ReadTimeout:       cfg.Duration(g.prefix.FlagName(HTTPReadTimeoutName)),
+		WriteTimeout:      cfg.Duration(g.prefix.FlagName(HTTPWriteTimeoutName)),
+		IdleTimeout:       cfg.Duration(g.prefix.FlagName(HTTPIdleTimeoutName)),
+		ReadHeaderTimeout: cfg.Duration(g.prefix.FlagName(HTTPReadHeaderTimeoutName)),
+		MaxHeaderBytes:    cfg.Int(g.prefix.FlagName(HTTPMaxHeaderBytesName)),
+		ClientAuth:        clientAuthTypes[cfg.String(g.prefix.FlagName(HTTPTLSClientAuthName))],
+		CORS: CORSParameters{
+			AllowedOrigins: cfg.StringArray(g.prefix.FlagName(HTTPCORSAllowedOriginsName)),
+			AllowedMethods: methods,
+			AllowedHeaders: cfg.StringArray(g.prefix.FlagName(HTTPCORSAllowedHeadersName)),
+		},
+	}, nil
+}
+
+// Validate validates the TLS parameters of the server and verifies that client certificates are only verified
+// if CA certs or the system cert pool are configured.
+func (g *HTTPServerGroup) Validate(params *HTTPServerParameters) error {
+	if err := g.tls.Validate(&params.TLSParameters); err != nil {
+		return err
+	}
+
+	verify := params.ClientAuth == tls.VerifyClientCertIfGiven || params.ClientAuth == tls.RequireAndVerifyClientCert
+
+	if verify && !params.SystemCertPool && len(params.CACerts) == 0 {
+		return fmt.Errorf("%s (%s) requires %s (%s) or %s (%s) to be set", g.prefix.FlagName(HTTPTLSClientAuthName),
+			g.prefix.EnvKey(HTTPTLSClientAuthName), g.tls.fields.CACertsFlagName, g.tls.fields.CACertsEnvKey,
+			g.tls.fields.SystemCertPoolFlagName, g.tls.fields.SystemCertPoolEnvKey)
+	}
+
+	return nil
+}
+
+// parseHostURL returns the host:port address of the given address or URL. The port of a URL defaults to
+// the port of its scheme.
+func parseHostURL(hostURL string) (string, error) {
+	address := hostURL
+
+	if strings.Contains(hostURL, "://") {
+		u, err := url.Parse(hostURL)
+		if err != nil {
+			return "", err
+		}
+
+		address = u.Host
+
+		if u.Port() == "" {
+			switch u.Scheme {
+			case "http":
+				address = net.JoinHostPort(u.Hostname(), "80")
+			case "https":
+				address = net.JoinHostPort(u.Hostname(), "443")
+			default:
+				return "", fmt.Errorf("port is required for scheme [%s]", u.Scheme)
+			}
+		}
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", err
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", errors.New("invalid port [" + port + "]")
+	}
+
+	return net.JoinHostPort(host, port), nil
+}
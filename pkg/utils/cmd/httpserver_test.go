@@ -0,0 +1,268 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestHTTPServerGroup(t *testing.T) {
+	group := cmd.NewHTTPServerGroup(cmd.GroupPrefix{Flag: "admin-", Env: "TEST_ADMIN_"})
+
+	t.Run("defaults", func(t *testing.T) {
+		params, err := cmd.ResolveGroup[*cmd.HTTPServerParameters](newCommand(t, group.AddFlags,
+			"--admin-host-url", "localhost:8080"), group)
+		require.NoError(t, err)
+		require.Equal(t, &cmd.HTTPServerParameters{
+			TLSParameters:     cmd.TLSParameters{CACerts: []string{}},
+			Address:           "localhost:8080",
+			ReadHeaderTimeout: cmd.DefaultHTTPReadHeaderTimeout,
+			CORS: cmd.CORSParameters{
+				AllowedOrigins: []string{},
+				AllowedMethods: []string{},
+				AllowedHeaders: []string{},
+			},
+		}, params)
+		require.False(t, params.TLSEnabled())
+
+		server, err := params.NewServer(http.NotFoundHandler())
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080", server.Addr)
+		require.Nil(t, server.TLSConfig)
+	})
+
+	t.Run("all parameters", func(t *testing.T) {
+		certPath, keyPath := writeTestKeyPair(t)
+
+		t.Setenv("TEST_ADMIN_HOST_URL", "https://0.0.0.0")
+		t.Setenv("TEST_ADMIN_READ_TIMEOUT", "30s")
+		t.Setenv("TEST_ADMIN_CORS_ALLOWED_ORIGINS", "https://example.com,https://other.com")
+		t.Setenv("TEST_ADMIN_TLS_CACERTS", certPath)
+
+		params, err := cmd.ResolveGroup[*cmd.HTTPServerParameters](newCommand(t, group.AddFlags,
+			"--admin-write-timeout", "1m", "--admin-idle-timeout", "2m", "--admin-read-header-timeout", "5s",
+			"--admin-max-header-bytes", "4096", "--admin-cors-allowed-methods", "get",
+			"--admin-cors-allowed-methods", " put",
+			"--admin-cors-allowed-headers", "Authorization", "--admin-tls-certificate", certPath,
+			"--admin-tls-key", keyPath), group)
+		require.NoError(t, err)
+		require.Equal(t, "0.0.0.0:443", params.Address)
+		require.Equal(t, 30*time.Second, params.ReadTimeout)
+		require.Equal(t, time.Minute, params.WriteTimeout)
+		require.Equal(t, 2*time.Minute, params.IdleTimeout)
+		require.Equal(t, 5*time.Second, params.ReadHeaderTimeout)
+		require.Equal(t, 4096, params.MaxHeaderBytes)
+		require.Equal(t, cmd.CORSParameters{
+			AllowedOrigins: []string{"https://example.com", "https://other.com"},
+			AllowedMethods: []string{http.MethodGet, http.MethodPut},
+			AllowedHeaders: []string{"Authorization"},
+		}, params.CORS)
+		require.Equal(t, []string{certPath}, params.CACerts)
+		require.Equal(t, certPath, params.ServeCertPath)
+		require.True(t, params.TLSEnabled())
+
+		server, err := params.NewServer(http.NotFoundHandler())
+		require.NoError(t, err)
+		require.Equal(t, "0.0.0.0:443", server.Addr)
+		require.Equal(t, 30*time.Second, server.ReadTimeout)
+		require.Equal(t, time.Minute, server.WriteTimeout)
+		require.Equal(t, 2*time.Minute, server.IdleTimeout)
+		require.Equal(t, 5*time.Second, server.ReadHeaderTimeout)
+		require.Equal(t, 4096, server.MaxHeaderBytes)
+		require.Len(t, server.TLSConfig.Certificates, 1)
+		require.NotNil(t, server.TLSConfig.ClientCAs)
+		require.Equal(t, tls.NoClientCert, server.TLSConfig.ClientAuth)
+		require.Equal(t, uint16(tls.VersionTLS12), server.TLSConfig.MinVersion)
+	})
+
+	t.Run("client auth", func(t *testing.T) {
+		certPath, keyPath := writeTestKeyPair(t)
+
+		params, err := cmd.ResolveGroup[*cmd.HTTPServerParameters](newCommand(t, group.AddFlags,
+			"--admin-host-url", ":8443", "--admin-tls-certificate", certPath, "--admin-tls-key", keyPath,
+			"--admin-tls-cacerts", certPath, "--admin-tls-client-auth", cmd.ClientAuthRequireAndVerify), group)
+		require.NoError(t, err)
+		require.Equal(t, tls.RequireAndVerifyClientCert, params.ClientAuth)
+
+		server, err := params.NewServer(http.NotFoundHandler())
+		require.NoError(t, err)
+		require.Equal(t, tls.RequireAndVerifyClientCert, server.TLSConfig.ClientAuth)
+
+		params, err = cmd.ResolveGroup[*cmd.HTTPServerParameters](newCommand(t, group.AddFlags,
+			"--admin-host-url", ":8443", "--admin-tls-cacerts", certPath, "--admin-tls-client-auth",
+			cmd.ClientAuthNone), group)
+		require.NoError(t, err)
+		require.Equal(t, tls.NoClientCert, params.ClientAuth)
+
+		_, err = cmd.ResolveGroup[*cmd.HTTPServerParameters](newCommand(t, group.AddFlags,
+			"--admin-host-url", ":8443", "--admin-tls-client-auth", cmd.ClientAuthVerifyIfGiven), group)
+		require.EqualError(t, err, "admin-tls-client-auth (TEST_ADMIN_TLS_CLIENT_AUTH) requires admin-tls-cacerts "+
+			"(TEST_ADMIN_TLS_CACERTS) or admin-tls-systemcertpool (TEST_ADMIN_TLS_SYSTEMCERTPOOL) to be set")
+
+		_, err = group.Resolve(newCommand(t, group.AddFlags, "--admin-host-url", ":8443",
+			"--admin-tls-client-auth", "always"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "admin-tls-client-auth")
+	})
+
+	t.Run("all errors", func(t *testing.T) {
+		t.Setenv("TEST_ADMIN_TLS_SYSTEMCERTPOOL", "maybe")
+
+		_, err := group.Resolve(newCommand(t, group.AddFlags, "--admin-host-url", "localhost"))
+
+		var validationErr *cmd.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errors, 2)
+		require.Contains(t, validationErr.Errors[0].Error(), "maybe")
+		require.Contains(t, validationErr.Errors[1].Error(), "invalid value for admin-host-url")
+
+		_, err = group.Resolve(newCommand(t, group.AddFlags, "--admin-idle-timeout", "-1s"))
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errors, 3)
+	})
+
+	t.Run("host URL", func(t *testing.T) {
+		for hostURL, address := range map[string]string{
+			":8080":                  ":8080",
+			"http://localhost":       "localhost:80",
+			"https://[::1]:8443/api": "[::1]:8443",
+		} {
+			params, err := group.Resolve(newCommand(t, group.AddFlags, "--admin-host-url", hostURL))
+			require.NoError(t, err)
+			require.Equal(t, address, params.Address)
+		}
+	})
+
+	t.Run("invalid host URL", func(t *testing.T) {
+		for _, hostURL := range []string{"localhost", "localhost:port", "tcp://localhost", "localhost:70000"} {
+			_, err := group.Resolve(newCommand(t, group.AddFlags, "--admin-host-url", hostURL))
+			require.Error(t, err)
+			require.Contains(t, err.Error(), "invalid value for admin-host-url")
+		}
+	})
+
+	t.Run("missing host URL", func(t *testing.T) {
+		_, err := group.Resolve(newCommand(t, group.AddFlags))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "admin-host-url")
+	})
+
+	t.Run("invalid timeout", func(t *testing.T) {
+		_, err := group.Resolve(newCommand(t, group.AddFlags, "--admin-host-url", ":8080",
+			"--admin-idle-timeout", "-1s"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "admin-idle-timeout")
+	})
+
+	t.Run("certificate without key", func(t *testing.T) {
+		certPath, _ := writeTestKeyPair(t)
+
+		_, err := cmd.ResolveGroup[*cmd.HTTPServerParameters](newCommand(t, group.AddFlags,
+			"--admin-host-url", ":8443", "--admin-tls-certificate", certPath), group)
+		require.EqualError(t, err, "admin-tls-certificate (TEST_ADMIN_TLS_CERTIFICATE) and admin-tls-key "+
+			"(TEST_ADMIN_TLS_KEY) must be set together")
+	})
+
+	t.Run("strict TLS", func(t *testing.T) {
+		strictGroup := cmd.NewHTTPServerGroup(cmd.GroupPrefix{Flag: "admin-", Env: "TEST_ADMIN_"},
+			cmd.WithRequiredCertificate())
+
+		_, err := strictGroup.Resolve(newCommand(t, strictGroup.AddFlags, "--admin-host-url", ":8443"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "admin-tls-certificate (TEST_ADMIN_TLS_CERTIFICATE)")
+	})
+
+	t.Run("invalid key pair", func(t *testing.T) {
+		params := &cmd.HTTPServerParameters{
+			TLSParameters: cmd.TLSParameters{ServeCertPath: "missing.pem", ServeKeyPath: "missing.key"},
+		}
+
+		_, err := params.NewServer(http.NotFoundHandler())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "load TLS key pair")
+	})
+
+	t.Run("parameters", func(t *testing.T) {
+		params := group.Parameters()
+		require.Len(t, params, 14)
+		require.Equal(t, "admin-host-url", params[0].FlagName)
+		require.Equal(t, "TEST_ADMIN_HOST_URL", params[0].EnvKey)
+		require.Equal(t, "admin-tls-client-auth", params[9].FlagName)
+		require.Equal(t, "admin-tls-systemcertpool", params[10].FlagName)
+		require.Equal(t, "admin-tls-systemcertpool", group.TLS().Fields().SystemCertPoolFlagName)
+	})
+}
+
+func TestCORSParameters_Handler(t *testing.T) {
+	cors := &cmd.CORSParameters{
+		AllowedOrigins: []string{"https://example.com"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+	}
+
+	handler := cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("allowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://example.com")
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "https://example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Equal(t, "GET, HEAD, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Authorization, Content-Type", rec.Header().Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://evil.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("wildcard origin", func(t *testing.T) {
+		params := &cmd.HTTPServerParameters{CORS: cmd.CORSParameters{AllowedOrigins: []string{"*"}}}
+
+		server, err := params.NewServer(http.NotFoundHandler())
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Origin", "https://any.com")
+
+		rec := httptest.NewRecorder()
+		server.Handler.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Equal(t, "https://any.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
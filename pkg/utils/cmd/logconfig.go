@@ -0,0 +1,77 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"github.com/trustbloc/logutil-go/pkg/log"
+
+	"github.com/trustbloc/cmdutil-go/internal/logfields"
+	tlsutil "github.com/trustbloc/cmdutil-go/pkg/utils/tls"
+)
+
+// LogConfig logs the effective configuration with the given logger: one entry per parameter with its name,
+// environment variable, source and value (redacted for secret parameters), followed by a summary of the TLS
+// parameters (if resolved).
+func LogConfig(logger *log.Log, cfg *Config) {
+	for _, v := range cfg.Values() {
+		logger.Info("Configuration parameter",
+			logfields.WithParameter(v.Parameter.FlagName),
+			logfields.WithEnvKey(v.Parameter.EnvKey),
+			logfields.WithSource(string(v.Source)),
+			logfields.WithValue(v.String()),
+		)
+	}
+
+	if cfg.TLS() != nil {
+		LogTLS(logger, cfg.TLS())
+	}
+}
+
+// LogTLS logs a summary of the given TLS parameters: whether the system cert pool is used, the number of
+// CA certs and the subject and expiry of the serving certificate.
+func LogTLS(logger *log.Log, params *TLSParameters) {
+	logger.Info("TLS configuration",
+		logfields.WithSystemCertPool(params.SystemCertPool),
+		logfields.WithCACertCount(len(params.CACerts)),
+	)
+
+	if params.ServeCertPath == "" {
+		return
+	}
+
+	cert, err := tlsutil.ParseCertificateFile(params.ServeCertPath)
+	if err != nil {
+		logger.Warn("Unable to read TLS serving certificate", logfields.WithCertPath(params.ServeCertPath),
+			log.WithError(err))
+
+		return
+	}
+
+	logger.Info("TLS serving certificate",
+		logfields.WithCertPath(params.ServeCertPath),
+		logfields.WithCertSubject(cert.Subject.String()),
+		logfields.WithCertExpiry(cert.NotAfter),
+	)
+}
+
+// LogFeatureGates logs the stage of each declared feature and whether it is enabled with the given logger.
+func LogFeatureGates(logger *log.Log, gates *FeatureGates) {
+	for _, name := range gates.Features() {
+		spec, _ := gates.Spec(name)
+
+		logger.Info("Feature gate",
+			logfields.WithFeature(string(name)),
+			logfields.WithStage(string(spec.Stage)),
+			logfields.WithEnabled(gates.Enabled(name)),
+		)
+	}
+}
+
+// LogSnapshot logs the fingerprint of the given configuration snapshot with the given logger so that
+// configuration drift between replicas may be detected from their logs.
+func LogSnapshot(logger *log.Log, snapshot *Snapshot) {
+	logger.Info("Configuration snapshot", logfields.WithFingerprint(snapshot.Fingerprint()))
+}
@@ -0,0 +1,145 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/trustbloc/logutil-go/pkg/log"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const testCert = `-----BEGIN CERTIFICATE-----
+MIICSDCCAe+gAwIBAgIQVy95bDHyGiHPiW/hN7iCEzAKBggqhkjOPQQDAjB2MQsw
+CQYDVQQGEwJVUzETMBEGA1UECBMKQ2FsaWZvcm5pYTEWMBQGA1UEBxMNU2FuIEZy
+YW5jaXNjbzEZMBcGA1UEChMQb3JnMS5leGFtcGxlLmNvbTEfMB0GA1UEAxMWdGxz
+Y2Eub3JnMS5leGFtcGxlLmNvbTAeFw0xODA3MjUxNDQxMjJaFw0yODA3MjIxNDQx
+MjJaMHYxCzAJBgNVBAYTAlVTMRMwEQYDVQQIEwpDYWxpZm9ybmlhMRYwFAYDVQQH
+Ew1TYW4gRnJhbmNpc2NvMRkwFwYDVQQKExBvcmcxLmV4YW1wbGUuY29tMR8wHQYD
+VQQDExZ0bHNjYS5vcmcxLmV4YW1wbGUuY29tMFkwEwYHKoZIzj0CAQYIKoZIzj0D
+AQcDQgAEMl8XK0Rpr514HXVut0MS/PX07l7gWeXGCQkl8T8LBuuSjGEkgSIuOwpf
+VqQv4TwXH0A8zIBrtxY2/W3/ERhhC6NfMF0wDgYDVR0PAQH/BAQDAgGmMA8GA1Ud
+JQQIMAYGBFUdJQAwDwYDVR0TAQH/BAUwAwEB/zApBgNVHQ4EIgQg+tqYPgAj39pQ
+2EH0hxR4SbPOmDRCmwiDsaVIj7tXIFYwCgYIKoZIzj0EAwIDRwAwRAIgUJVxM/57
+1WMfcy56D2zw6g9APP5Z3g+Qg/Y5cScstkgCIBj0JVuemNxiQWdXZ/Qhc6sh4m5d
+ngzYatfQtNv3/+4V
+-----END CERTIFICATE-----`
+
+func TestLogConfig(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL"},
+		&cmd.Parameter{FlagName: "api-token", EnvKey: "TEST_API_TOKEN", Secret: true},
+		&cmd.Parameter{FlagName: "cas-url", EnvKey: "TEST_CAS_URL", Type: cmd.StringArrayType},
+	))
+
+	certFile := filepath.Join(t.TempDir(), "cert.pem")
+	require.NoError(t, os.WriteFile(certFile, []byte(testCert), 0o600))
+
+	t.Setenv("TEST_HOST_URL", "localhost:8080")
+	t.Setenv("TEST_API_TOKEN", "secret-token")
+	t.Setenv("TEST_TLS_CACERTS", "ca1.pem,ca2.pem")
+	t.Setenv("TEST_TLS_CERTIFICATE", certFile)
+
+	cfg, err := r.Resolve(&cobra.Command{Use: "start"}, cmd.WithTLS(newTestTLSFields()))
+	require.NoError(t, err)
+
+	out := &bytes.Buffer{}
+
+	cmd.LogConfig(log.New("test", log.WithStdOut(&syncWriter{out}), log.WithEncoding(log.JSON)), cfg)
+
+	entries := readLogEntries(t, out)
+	require.Len(t, entries, 5)
+
+	require.Equal(t, "Configuration parameter", entries[0]["msg"])
+	require.Equal(t, "host-url", entries[0]["parameter"])
+	require.Equal(t, "TEST_HOST_URL", entries[0]["envKey"])
+	require.Equal(t, "env", entries[0]["source"])
+	require.Equal(t, "localhost:8080", entries[0]["value"])
+
+	require.Equal(t, "api-token", entries[1]["parameter"])
+	require.Equal(t, cmd.RedactedValue, entries[1]["value"])
+	require.NotContains(t, out.String(), "secret-token")
+
+	require.Equal(t, "cas-url", entries[2]["parameter"])
+	require.Equal(t, "default", entries[2]["source"])
+	require.Equal(t, "", entries[2]["value"])
+
+	require.Equal(t, "TLS configuration", entries[3]["msg"])
+	require.Equal(t, false, entries[3]["systemCertPool"])
+	require.Equal(t, float64(2), entries[3]["caCertCount"])
+
+	require.Equal(t, "TLS serving certificate", entries[4]["msg"])
+	require.Equal(t, certFile, entries[4]["certPath"])
+	require.Contains(t, entries[4]["certSubject"], "CN=tlsca.org1.example.com")
+	require.Contains(t, entries[4]["certExpiry"], "2028-07-22")
+}
+
+func TestLogTLS(t *testing.T) {
+	out := &bytes.Buffer{}
+
+	cmd.LogTLS(log.New("test", log.WithStdOut(&syncWriter{out}), log.WithEncoding(log.JSON)), &cmd.TLSParameters{
+		SystemCertPool: true,
+		ServeCertPath:  "invalid.pem",
+	})
+
+	entries := readLogEntries(t, out)
+	require.Len(t, entries, 2)
+	require.Equal(t, true, entries[0]["systemCertPool"])
+	require.Equal(t, "Unable to read TLS serving certificate", entries[1]["msg"])
+	require.Contains(t, entries[1]["error"], "failed to read cert")
+}
+
+func readLogEntries(t *testing.T, out *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+
+	scanner := bufio.NewScanner(out)
+	for scanner.Scan() {
+		entry := make(map[string]interface{})
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+type syncWriter struct {
+	*bytes.Buffer
+}
+
+func (w *syncWriter) Sync() error {
+	return nil
+}
+
+func TestLogSnapshot(t *testing.T) {
+	r := cmd.NewRegistry()
+	require.NoError(t, r.Register(&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL"}))
+
+	cfg, err := r.Resolve(&cobra.Command{Use: "start"})
+	require.NoError(t, err)
+
+	snapshot := cfg.Snapshot()
+	out := &bytes.Buffer{}
+
+	cmd.LogSnapshot(log.New("test", log.WithStdOut(&syncWriter{out}), log.WithEncoding(log.JSON)), snapshot)
+
+	entries := readLogEntries(t, out)
+	require.Len(t, entries, 1)
+	require.Equal(t, "Configuration snapshot", entries[0]["msg"])
+	require.Equal(t, snapshot.Fingerprint(), entries[0]["fingerprint"])
+}
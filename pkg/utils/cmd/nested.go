@@ -0,0 +1,619 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	nestedEnvSeparator  = "__"
+	nestedEnvIndexSep   = "_"
+	nestedFlagSeparator = "."
+
+	// maxNestedIndex is the highest index of an element of a slice decoded with GetNested.
+	maxNestedIndex = 9999
+)
+
+// Validator is implemented by values decoded with GetNested which validate themselves. Each element of a slice
+// is validated separately so that errors identify the invalid element.
+type Validator interface {
+	Validate() error
+}
+
+// GetNested decodes a list of structured entries or a nested structure set via either command line flag or
+// environment variables into the value pointed to by v, which must be a pointer to a slice or to a struct.
+// If both are set, then the command line flag takes precedence.
+//
+// For the environment variables, the elements of a slice are set with indexed variables and the fields of
+// nested structs are separated with a double underscore. For example, with the envKey ISSUERS:
+//
+//	ISSUERS_0_NAME=a ISSUERS_0_URL=https://a.example.com ISSUERS_0_POOL__MAX_SIZE=10 ISSUERS_1_NAME=b
+//
+// and, for a struct with the envKey DB:
+//
+//	DB__URL=mongodb://localhost DB__POOL__MAX_SIZE=10
+//
+// Indices must be contiguous from 0. Alternatively, the envKey variable itself may be set to a JSON or YAML
+// document (or to "@path" to load it from a file), as with GetStructured.
+//
+// For the command line flag, which must be set as a StringArray, each occurrence sets one element of a slice
+// (or some fields of a struct) as comma-separated key=value entries in which the fields of nested structs are
+// separated with a dot (e.g. --issuer name=a,url=https://a.example.com,pool.max-size=10 --issuer name=b).
+// A single occurrence set to "@path" loads a JSON or YAML document from a file instead.
+//
+// Field names are matched with the JSON field tags (or the field names) of v ignoring case, underscores and
+// dashes, and unknown fields are rejected. Scalar values are parsed according to the field type (durations
+// with ParseDuration) and a slice of scalars may also be set as a comma-separated list. Values implementing
+// Validator (the struct or each element of the slice) are validated after decoding.
+// If the variable isn't set and isOptional is true, then v is left unchanged.
+func GetNested(cmd *cobra.Command, flagName, envKey string, v interface{}, isOptional bool) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return fmt.Errorf("decode %s: expecting a non-nil pointer", envKey)
+	}
+
+	isSlice := rv.Elem().Kind() == reflect.Slice
+
+	if !isSlice && rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("decode %s: expecting a pointer to a slice or to a struct", envKey)
+	}
+
+	if cmd.Flags().Changed(flagName) {
+		values, err := cmd.Flags().GetStringArray(flagName)
+		if err != nil {
+			return fmt.Errorf(flagName+" flag not found: %s", err)
+		}
+
+		if len(values) == 0 {
+			return fmt.Errorf("%s value is empty", flagName)
+		}
+
+		return decodeNestedFlag(cmd, flagName, values, rv, isSlice)
+	}
+
+	value, isSet := os.LookupEnv(envKey)
+	vars := nestedEnvVars(envKey, isSlice)
+
+	switch {
+	case isSet && len(vars) > 0:
+		return fmt.Errorf("%s and %s must not be set together", envKey, strings.Join(vars, ", "))
+	case isSet:
+		return decodeNestedDocument(cmd, envKey, value, rv, isSlice)
+	case len(vars) > 0:
+		root, err := parseNestedEnv(cmd, envKey, vars, isSlice)
+		if err != nil {
+			return err
+		}
+
+		return decodeNestedTree(root, rv, isSlice, indexedEnvName)
+	case isOptional:
+		return nil
+	}
+
+	return errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
+		" (environment variable) have been set.")
+}
+
+func decodeNestedFlag(cmd *cobra.Command, flagName string, values []string, rv reflect.Value, isSlice bool) error {
+	if _, ok := valueFile(values[0]); ok && len(values) == 1 {
+		return decodeNestedDocument(cmd, flagName, values[0], rv, isSlice)
+	}
+
+	root := &nestedNode{name: flagName}
+
+	for i, value := range values {
+		entries, err := parseStringMap(cmd, flagName, splitEscaped(value, mapEntrySeparator), DuplicateKeyError,
+			resolveValue)
+		if err != nil {
+			return err
+		}
+
+		node := root
+
+		if isSlice {
+			node = root.child(strconv.Itoa(i), indexedFlagName(flagName, i))
+		}
+
+		for key, v := range entries {
+			if err := node.setPath(strings.Split(key, nestedFlagSeparator), v, nestedFlagSeparator); err != nil {
+				return err
+			}
+		}
+	}
+
+	return decodeNestedTree(root, rv, isSlice, indexedFlagName)
+}
+
+func decodeNestedDocument(cmd *cobra.Command, name, value string, rv reflect.Value, isSlice bool) error {
+	resolved, err := resolveFileValue(cmd, name, value)
+	if err != nil {
+		return err
+	}
+
+	if resolved == "" {
+		return fmt.Errorf("%s value is empty", name)
+	}
+
+	target := reflect.New(rv.Elem().Type())
+
+	if err := DecodeStructured([]byte(resolved), target.Interface()); err != nil {
+		if path, ok := valueFile(value); ok {
+			return fmt.Errorf("invalid value for %s (file %s): %w", name, path, err)
+		}
+
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	if err := validateNested(name, target.Elem(), isSlice, indexedFlagName); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(target.Elem())
+
+	return nil
+}
+
+func decodeNestedTree(root *nestedNode, rv reflect.Value, isSlice bool, elemName func(string, int) string) error {
+	target := reflect.New(rv.Elem().Type())
+
+	if err := root.assign(target.Elem()); err != nil {
+		return err
+	}
+
+	if err := validateNested(root.name, target.Elem(), isSlice, elemName); err != nil {
+		return err
+	}
+
+	rv.Elem().Set(target.Elem())
+
+	return nil
+}
+
+func validateNested(name string, rv reflect.Value, isSlice bool, elemName func(string, int) string) error {
+	if !isSlice {
+		return validateNestedValue(name, rv)
+	}
+
+	for i := 0; i < rv.Len(); i++ {
+		if err := validateNestedValue(elemName(name, i), rv.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateNestedValue(name string, rv reflect.Value) error {
+	if rv.Kind() == reflect.Pointer && rv.IsNil() {
+		return nil
+	}
+
+	validator, ok := rv.Interface().(Validator)
+	if !ok && rv.CanAddr() {
+		validator, ok = rv.Addr().Interface().(Validator)
+	}
+
+	if !ok {
+		return nil
+	}
+
+	if err := validator.Validate(); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func indexedEnvName(name string, i int) string {
+	return name + nestedEnvIndexSep + strconv.Itoa(i)
+}
+
+func indexedFlagName(name string, i int) string {
+	return fmt.Sprintf("%s[%d]", name, i)
+}
+
+// nestedEnvVars returns the sorted names of the environment variables which set the elements of a slice
+// (e.g. ISSUERS_0_NAME) or the fields of a struct (e.g. DB__URL) for the given envKey.
+func nestedEnvVars(envKey string, isSlice bool) []string {
+	var vars []string
+
+	for _, kv := range os.Environ() {
+		key := strings.SplitN(kv, "=", 2)[0] //nolint:gomnd // key and value
+
+		if isNestedEnvKey(key, envKey, isSlice) {
+			vars = append(vars, key)
+		}
+	}
+
+	sort.Strings(vars)
+
+	return vars
+}
+
+// isNestedEnvKey returns true if key sets an element of a slice (e.g. ISSUERS_0_NAME) or a field of a
+// struct (e.g. DB__URL) for the given envKey.
+func isNestedEnvKey(key, envKey string, isSlice bool) bool {
+	if isSlice {
+		rest := strings.TrimPrefix(key, envKey+nestedEnvIndexSep)
+
+		return rest != key && rest != "" && rest[0] >= '0' && rest[0] <= '9'
+	}
+
+	return strings.HasPrefix(key, envKey+nestedEnvSeparator) && len(key) > len(envKey+nestedEnvSeparator)
+}
+
+func parseNestedEnv(cmd *cobra.Command, envKey string, vars []string, isSlice bool) (*nestedNode, error) {
+	root := &nestedNode{name: envKey}
+
+	for _, key := range vars {
+		rest := strings.TrimPrefix(key, envKey+nestedEnvSeparator)
+		if isSlice {
+			rest = strings.TrimPrefix(key, envKey+nestedEnvIndexSep)
+		}
+
+		value, err := resolveValue(cmd, key, os.Getenv(key))
+		if err != nil {
+			return nil, err
+		}
+
+		path := splitNestedEnvKey(rest)
+		if isSlice {
+			path[0] = nestedEnvIndexSep + path[0]
+		} else {
+			path[0] = nestedEnvSeparator + path[0]
+		}
+
+		if err := root.setPath(path, value, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+// splitNestedEnvKey splits the given environment variable suffix into the path of a nested value. Segments
+// are separated with a double underscore. Within a segment, numeric tokens separated with an underscore are
+// indices and the other tokens are joined into a field name (e.g. "0_POOL__MAX_SIZE" becomes 0, POOL and
+// MAX_SIZE). The returned path elements retain their separators so that names can be rebuilt from them.
+func splitNestedEnvKey(key string) []string {
+	var path []string
+
+	for i, segment := range strings.Split(key, nestedEnvSeparator) {
+		sep := nestedEnvSeparator
+		if i == 0 {
+			sep = ""
+		}
+
+		var name []string
+
+		for _, token := range strings.Split(segment, nestedEnvIndexSep) {
+			if isIndex(token) {
+				if len(name) > 0 {
+					path = append(path, sep+strings.Join(name, nestedEnvIndexSep))
+					name, sep = nil, nestedEnvIndexSep
+				}
+
+				path = append(path, sep+token)
+				sep = nestedEnvIndexSep
+
+				continue
+			}
+
+			name = append(name, token)
+		}
+
+		if len(name) > 0 {
+			path = append(path, sep+strings.Join(name, nestedEnvIndexSep))
+		}
+	}
+
+	return path
+}
+
+func isIndex(token string) bool {
+	if token == "" {
+		return false
+	}
+
+	for _, r := range token {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// nestedNode is a node of the tree of nested values parsed from environment variables or repeated flags.
+// Children are keyed by index or by normalized field name.
+type nestedNode struct {
+	name     string
+	value    *string
+	children map[string]*nestedNode
+}
+
+func (n *nestedNode) child(key, name string) *nestedNode {
+	if n.children == nil {
+		n.children = make(map[string]*nestedNode)
+	}
+
+	c, ok := n.children[key]
+	if !ok {
+		c = &nestedNode{name: name}
+		n.children[key] = c
+	}
+
+	return c
+}
+
+// setPath sets the value of the node at the given path. The name of each node is the name of its parent
+// followed by sep and the path element, which may itself start with a separator (e.g. "_0" or "__POOL").
+func (n *nestedNode) setPath(path []string, value, sep string) error {
+	node := n
+
+	for _, elem := range path {
+		if node.value != nil {
+			return fmt.Errorf("invalid value for %s: both a value and nested keys are set", node.name)
+		}
+
+		name := node.name + sep + elem
+
+		key := strings.TrimLeft(elem, nestedEnvIndexSep)
+		if !isIndex(key) {
+			key = normalizeFieldName(key)
+		}
+
+		if key == "" {
+			return fmt.Errorf("invalid value for %s: field name is empty", name)
+		}
+
+		node = node.child(key, name)
+	}
+
+	switch {
+	case node.value != nil:
+		return fmt.Errorf("invalid value for %s: value is set more than once", node.name)
+	case node.children != nil:
+		return fmt.Errorf("invalid value for %s: both a value and nested keys are set", node.name)
+	}
+
+	node.value = &value
+
+	return nil
+}
+
+func (n *nestedNode) assign(rv reflect.Value) error {
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			rv.Set(reflect.New(rv.Type().Elem()))
+		}
+
+		return n.assign(rv.Elem())
+	}
+
+	if n.value != nil {
+		if err := setNestedScalar(rv, *n.value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", n.name, err)
+		}
+
+		return nil
+	}
+
+	switch rv.Kind() { //nolint:exhaustive // other kinds aren't supported
+	case reflect.Struct:
+		return n.assignStruct(rv)
+	case reflect.Slice:
+		return n.assignSlice(rv)
+	default:
+		return fmt.Errorf("invalid value for %s: expecting a value of type %s rather than nested keys",
+			n.name, rv.Type())
+	}
+}
+
+func (n *nestedNode) assignStruct(rv reflect.Value) error {
+	fields := make(map[string]int, rv.NumField())
+
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Type().Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name := f.Name
+
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fields[normalizeFieldName(name)] = i
+	}
+
+	for _, key := range n.sortedKeys() {
+		c := n.children[key]
+
+		i, ok := fields[key]
+		if !ok {
+			return fmt.Errorf("invalid value for %s: unknown field", c.name)
+		}
+
+		if err := c.assign(rv.Field(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *nestedNode) assignSlice(rv reflect.Value) error {
+	length := len(n.children)
+
+	for key, c := range n.children {
+		i, err := strconv.Atoi(key)
+		if err != nil {
+			return fmt.Errorf("invalid value for %s: expecting an index", c.name)
+		}
+
+		if i > maxNestedIndex {
+			return fmt.Errorf("invalid value for %s: index %d exceeds the maximum of %d", c.name, i, maxNestedIndex)
+		}
+
+		// The indices are distinct so they are contiguous from 0 if and only if they are all less than the
+		// number of elements. This is checked before the slice is allocated.
+		if i >= length {
+			return fmt.Errorf("invalid value for %s: index %d is missing, indices must be contiguous from 0",
+				n.name, n.missingIndex())
+		}
+	}
+
+	s := reflect.MakeSlice(rv.Type(), length, length)
+
+	for i := 0; i < length; i++ {
+		c, ok := n.children[strconv.Itoa(i)]
+		if !ok {
+			return fmt.Errorf("invalid value for %s: index %d is missing, indices must be contiguous from 0",
+				n.name, i)
+		}
+
+		if err := c.assign(s.Index(i)); err != nil {
+			return err
+		}
+	}
+
+	rv.Set(s)
+
+	return nil
+}
+
+// missingIndex returns the lowest index which isn't set.
+func (n *nestedNode) missingIndex() int {
+	i := 0
+
+	for {
+		if _, ok := n.children[strconv.Itoa(i)]; !ok {
+			return i
+		}
+
+		i++
+	}
+}
+
+func (n *nestedNode) sortedKeys() []string {
+	keys := make([]string, 0, len(n.children))
+
+	for k := range n.children {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+func setNestedScalar(rv reflect.Value, value string) error {
+	if rv.CanAddr() {
+		if u, ok := rv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(value))
+		}
+	}
+
+	if rv.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := ParseDuration(value)
+		if err != nil {
+			return err
+		}
+
+		rv.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch rv.Kind() { //nolint:exhaustive // other kinds aren't supported
+	case reflect.String:
+		rv.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+
+		rv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		rv.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 10, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		rv.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, rv.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		rv.SetFloat(f)
+	case reflect.Slice:
+		return setNestedList(rv, value)
+	case reflect.Pointer:
+		rv.Set(reflect.New(rv.Type().Elem()))
+
+		return setNestedScalar(rv.Elem(), value)
+	default:
+		return fmt.Errorf("unsupported type %s", rv.Type())
+	}
+
+	return nil
+}
+
+func setNestedList(rv reflect.Value, value string) error {
+	var items []string
+
+	if value != "" {
+		items = strings.Split(value, ",")
+	}
+
+	s := reflect.MakeSlice(rv.Type(), len(items), len(items))
+
+	for i, item := range items {
+		if err := setNestedScalar(s.Index(i), strings.TrimSpace(item)); err != nil {
+			return err
+		}
+	}
+
+	rv.Set(s)
+
+	return nil
+}
+
+// normalizeFieldName returns the given field name in lower case without underscores and dashes so that
+// MAX_POOL_SIZE, max-pool-size and MaxPoolSize match.
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
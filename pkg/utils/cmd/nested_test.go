@@ -0,0 +1,280 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const (
+	issuerFlagName = "issuer"
+	issuerEnvKey   = "TEST_ISSUERS"
+	dbFlagName     = "db"
+	dbEnvKey       = "TEST_DB"
+)
+
+type testPool struct {
+	MaxSize int           `json:"maxSize"`
+	Timeout time.Duration `json:"timeout"`
+}
+
+type testIssuer struct {
+	Name    string    `json:"name"`
+	URL     string    `json:"url"`
+	Enabled *bool     `json:"enabled,omitempty"`
+	Tags    []string  `json:"tags,omitempty"`
+	Pool    *testPool `json:"pool,omitempty"`
+}
+
+func (i *testIssuer) Validate() error {
+	if i.Name == "" {
+		return errors.New("name is required")
+	}
+
+	return nil
+}
+
+type testDB struct {
+	URL     string   `json:"url"`
+	Pool    testPool `json:"pool"`
+	Ignored string   `json:"-"`
+}
+
+func TestGetNested(t *testing.T) {
+	enabled := true
+
+	expectedIssuers := []testIssuer{
+		{
+			Name:    "a",
+			URL:     "https://a.example.com",
+			Enabled: &enabled,
+			Tags:    []string{"x", "y"},
+			Pool:    &testPool{MaxSize: 10, Timeout: time.Minute},
+		},
+		{Name: "b"},
+	}
+
+	t.Run("indexed environment variables", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_0_URL", "https://a.example.com")
+		t.Setenv("TEST_ISSUERS_0_ENABLED", "true")
+		t.Setenv("TEST_ISSUERS_0_TAGS_0", "x")
+		t.Setenv("TEST_ISSUERS_0_TAGS_1", "y")
+		t.Setenv("TEST_ISSUERS_0_POOL__MAX_SIZE", "10")
+		t.Setenv("TEST_ISSUERS_0_POOL__TIMEOUT", "1m")
+		t.Setenv("TEST_ISSUERS_1_NAME", "b")
+
+		var issuers []testIssuer
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false))
+		require.Equal(t, expectedIssuers, issuers)
+	})
+
+	t.Run("repeated flags", func(t *testing.T) {
+		var issuers []testIssuer
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags, "--issuer",
+			"name=a,url=https://a.example.com,enabled=true,tags=x\\,y,pool.max-size=10,pool.timeout=PT1M",
+			"--issuer", "name=b"), issuerFlagName, issuerEnvKey, &issuers, false))
+		require.Equal(t, expectedIssuers, issuers)
+	})
+
+	t.Run("config file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "issuers.yaml")
+		require.NoError(t, os.WriteFile(file, []byte(`
+- name: a
+  url: https://a.example.com
+  enabled: true
+  tags: [x, y]
+  pool:
+    maxSize: 10
+    timeout: 60000000000
+- name: b
+`), 0o600))
+
+		var issuers []testIssuer
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags, "--issuer", "@"+file), issuerFlagName, issuerEnvKey,
+			&issuers, false))
+		require.Equal(t, expectedIssuers, issuers)
+
+		t.Setenv(issuerEnvKey, "@"+file)
+
+		issuers = nil
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false))
+		require.Equal(t, expectedIssuers, issuers)
+	})
+
+	t.Run("flag takes precedence", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "env")
+
+		var issuers []testIssuer
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags, "--issuer", "name=flag"), issuerFlagName,
+			issuerEnvKey, &issuers, false))
+		require.Equal(t, []testIssuer{{Name: "flag"}}, issuers)
+	})
+
+	t.Run("nested struct", func(t *testing.T) {
+		t.Setenv("TEST_DB__URL", "mongodb://localhost")
+		t.Setenv("TEST_DB__POOL__MAX_SIZE", "5")
+		t.Setenv("TEST_DB_TYPE", "ignored since it isn't nested")
+
+		var db testDB
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags), dbFlagName, dbEnvKey, &db, false))
+		require.Equal(t, testDB{URL: "mongodb://localhost", Pool: testPool{MaxSize: 5}}, db)
+
+		db = testDB{}
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags, "--db", "url=mongodb://other", "--db",
+			"pool.timeout=5s"), dbFlagName, dbEnvKey, &db, false))
+		require.Equal(t, testDB{URL: "mongodb://other", Pool: testPool{Timeout: 5 * time.Second}}, db)
+	})
+
+	t.Run("gap in indices", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_2_NAME", "c")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "invalid value for TEST_ISSUERS: index 1 is missing, indices must be "+
+			"contiguous from 0")
+		require.Nil(t, issuers)
+	})
+
+	t.Run("index too large", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_2000000000_NAME", "b")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "invalid value for TEST_ISSUERS_2000000000: index 2000000000 exceeds "+
+			"the maximum of 9999")
+		require.Nil(t, issuers)
+	})
+
+	t.Run("element validation", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_1_URL", "https://b.example.com")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "invalid value for TEST_ISSUERS_1: name is required")
+
+		err = cmd.GetNested(newCommand(t, nestedFlags, "--issuer", "name=a", "--issuer", "url=b"), issuerFlagName,
+			issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "invalid value for issuer[1]: name is required")
+	})
+
+	t.Run("unknown field", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_0_POOL__SIZE", "1")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "invalid value for TEST_ISSUERS_0_POOL__SIZE: unknown field")
+
+		err = cmd.GetNested(newCommand(t, nestedFlags, "--issuer", "nmae=a"), issuerFlagName, issuerEnvKey,
+			&issuers, false)
+		require.EqualError(t, err, "invalid value for issuer[0].nmae: unknown field")
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_0_POOL__MAX_SIZE", "ten")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for TEST_ISSUERS_0_POOL__MAX_SIZE: strconv.ParseInt")
+	})
+
+	t.Run("value and nested keys", func(t *testing.T) {
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+		t.Setenv("TEST_ISSUERS_0_POOL", "10")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for TEST_ISSUERS_0_POOL")
+
+		err = cmd.GetNested(newCommand(t, nestedFlags, "--issuer", "name=a,name.first=b"), issuerFlagName,
+			issuerEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for issuer[0].name")
+	})
+
+	t.Run("document and indexed variables", func(t *testing.T) {
+		t.Setenv(issuerEnvKey, `[{"name":"a"}]`)
+		t.Setenv("TEST_ISSUERS_0_NAME", "a")
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "TEST_ISSUERS and TEST_ISSUERS_0_NAME must not be set together")
+	})
+
+	t.Run("invalid document", func(t *testing.T) {
+		t.Setenv(issuerEnvKey, `[{"name":"a","unknown":1}]`)
+
+		var issuers []testIssuer
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for TEST_ISSUERS: decode JSON")
+
+		t.Setenv(issuerEnvKey, `[{"url":"a"}]`)
+
+		err = cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "invalid value for TEST_ISSUERS[0]: name is required")
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		issuers := []testIssuer{{Name: "default"}}
+
+		require.NoError(t, cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, true))
+		require.Equal(t, []testIssuer{{Name: "default"}}, issuers)
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &issuers, false)
+		require.EqualError(t, err, "Neither issuer (command line flag) nor TEST_ISSUERS (environment variable) "+
+			"have been set.")
+	})
+
+	t.Run("invalid target", func(t *testing.T) {
+		var s string
+
+		err := cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, &s, true)
+		require.EqualError(t, err, "decode TEST_ISSUERS: expecting a pointer to a slice or to a struct")
+
+		err = cmd.GetNested(newCommand(t, nestedFlags), issuerFlagName, issuerEnvKey, nil, true)
+		require.EqualError(t, err, "decode TEST_ISSUERS: expecting a non-nil pointer")
+	})
+}
+
+// nestedFlags adds the issuer and database flags.
+func nestedFlags(command *cobra.Command) error {
+	command.Flags().StringArray(issuerFlagName, nil, "")
+	command.Flags().StringArray(dbFlagName, nil, "")
+
+	return nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// MaxPromptAttempts is the number of times the value of a parameter is prompted for before the resolution
+// fails with the last validation error.
+const MaxPromptAttempts = 3
+
+// Prompter prompts a user for the values of required parameters which aren't set.
+type Prompter interface {
+	// Interactive returns true if the user may be prompted, i.e. the input is a terminal.
+	Interactive() bool
+	// Prompt writes the given prompt and reads a line of input. The input isn't echoed if masked is true.
+	Prompt(prompt string, masked bool) (string, error)
+	// Invalid reports that the value entered by the user is invalid.
+	Invalid(err error)
+}
+
+// WithPrompt prompts for the values of required parameters which aren't set when the standard input is
+// a terminal. The values of secret parameters are read without being echoed. The prompts are written to the
+// standard error. When the standard input isn't a terminal, the resolution fails as without this option.
+func WithPrompt() ResolveOption {
+	return WithPrompter(NewTerminalPrompter(os.Stdin, os.Stderr))
+}
+
+// WithPrompter prompts for the values of required parameters which aren't set with the given prompter.
+func WithPrompter(prompter Prompter) ResolveOption {
+	return func(opts *resolveOptions) {
+		opts.prompter = prompter
+	}
+}
+
+// TerminalPrompter prompts for values on a terminal.
+type TerminalPrompter struct {
+	in     *os.File
+	out    io.Writer
+	reader *bufio.Reader
+}
+
+// NewTerminalPrompter returns a prompter which reads values from the given input and writes prompts to the
+// given output.
+func NewTerminalPrompter(in *os.File, out io.Writer) *TerminalPrompter {
+	return &TerminalPrompter{
+		in:     in,
+		out:    out,
+		reader: bufio.NewReader(in),
+	}
+}
+
+// Interactive returns true if the input is a terminal.
+func (t *TerminalPrompter) Interactive() bool {
+	return term.IsTerminal(int(t.in.Fd()))
+}
+
+// Prompt writes the given prompt and reads a line of input. If masked is true and the input is a terminal,
+// the input isn't echoed.
+func (t *TerminalPrompter) Prompt(prompt string, masked bool) (string, error) {
+	fmt.Fprint(t.out, prompt)
+
+	if masked && t.Interactive() {
+		b, err := term.ReadPassword(int(t.in.Fd()))
+
+		fmt.Fprintln(t.out)
+
+		return string(b), err
+	}
+
+	line, err := t.reader.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// Invalid writes the given validation error.
+func (t *TerminalPrompter) Invalid(err error) {
+	fmt.Fprintf(t.out, "Invalid value: %s\n", err)
+}
+
+// isPromptable returns true if the value of the given parameter is to be prompted for.
+func isPromptable(cmd *cobra.Command, p *Parameter, prompter Prompter) bool {
+	if prompter == nil || !p.Required || cmd.Flags().Lookup(p.FlagName) == nil {
+		return false
+	}
+
+	if source, _ := sourceOf(cmd, p); source != SourceDefault {
+		return false
+	}
+
+	return prompter.Interactive()
+}
+
+// promptParameter prompts for the value of the given parameter and parses it according to the type of the
+// parameter. The prompted value is taken literally: it isn't loaded from a file, obtained from a secret
+// provider or decrypted. An invalid value is reported and prompted for again, up to MaxPromptAttempts times.
+func promptParameter(p *Parameter, prompter Prompter) (*Value, error) {
+	prompt := promptText(p)
+
+	var err error
+
+	for attempt := 0; attempt < MaxPromptAttempts; attempt++ {
+		if err != nil {
+			prompter.Invalid(err)
+		}
+
+		var input string
+
+		input, err = prompter.Prompt(prompt, p.Secret)
+		if err != nil {
+			return nil, fmt.Errorf("read value for %s: %w", p.FlagName, err)
+		}
+
+		if strings.TrimSpace(input) == "" {
+			err = fmt.Errorf("a value for %s is required", p.FlagName)
+
+			continue
+		}
+
+		var value interface{}
+
+		value, err = parsePromptedValue(p, input)
+		if err != nil {
+			continue
+		}
+
+		if err = validateValue(p, value); err == nil {
+			return &Value{Parameter: p, Value: value, Source: SourcePrompt, Secret: p.Secret}, nil
+		}
+	}
+
+	return nil, err
+}
+
+func promptText(p *Parameter) string {
+	prompt := p.FlagName
+
+	if p.Description != "" {
+		prompt += " (" + strings.TrimSuffix(p.Description, ".") + ")"
+	}
+
+	if enum := p.enum(); enum != nil {
+		prompt += " [" + strings.Join(enum.Values(), ", ") + "]"
+	}
+
+	return prompt + ": "
+}
+
+// parsePromptedValue parses the prompted input according to the type of the given parameter. The values of
+// array parameters are separated by commas.
+func parsePromptedValue(p *Parameter, input string) (interface{}, error) {
+	var (
+		value interface{}
+		err   error
+	)
+
+	switch p.Type {
+	case StringArrayType, CSVType:
+		var values []string
+
+		for _, v := range strings.Split(input, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+
+		return values, nil
+	case StringMapType:
+		value, err = ParseStringMap(input, p.DuplicateKeys)
+	case BoolType:
+		value, err = strconv.ParseBool(input)
+	case IntType:
+		value, err = strconv.Atoi(input)
+	case FloatType:
+		value, err = strconv.ParseFloat(input, 64)
+	case DurationType:
+		value, err = parsePromptedDuration(p, input)
+	default:
+		return parsePromptedString(p, input)
+	}
+
+	switch {
+	case err == nil:
+		return value, nil
+	case p.Secret:
+		// The input of a secret parameter isn't echoed so it mustn't appear in the error either.
+		return nil, fmt.Errorf("invalid value for %s: expecting a value of type %s", p.FlagName, p.Type)
+	default:
+		return nil, fmt.Errorf("invalid value for %s [%s]: %w", p.FlagName, input, err)
+	}
+}
+
+func parsePromptedString(p *Parameter, input string) (string, error) {
+	enum := p.enum()
+	if enum == nil {
+		return input, nil
+	}
+
+	if value, ok := enum.Match(input); ok {
+		return value, nil
+	}
+
+	if p.Secret {
+		return "", fmt.Errorf("invalid value for %s: allowed values are %s", p.FlagName, enum)
+	}
+
+	return "", fmt.Errorf("invalid value for %s [%s]: allowed values are %s", p.FlagName, input, enum)
+}
+
+func parsePromptedDuration(p *Parameter, input string) (time.Duration, error) {
+	value, err := ParseDuration(input)
+	if err != nil {
+		return 0, err
+	}
+
+	if p.DurationBounds != nil {
+		if err := p.DurationBounds.check(value); err != nil {
+			return 0, err
+		}
+	}
+
+	return value, nil
+}
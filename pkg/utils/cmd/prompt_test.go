@@ -0,0 +1,287 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestPrompt(t *testing.T) {
+	r := newPromptRegistry(t)
+
+	t.Run("success", func(t *testing.T) {
+		prompter := &mockPrompter{interactive: true, inputs: []string{"s3cret", "mongo", "a, b", "5"}}
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--host-url", "localhost:8080"), cmd.WithPrompter(prompter))
+		require.NoError(t, err)
+		require.Equal(t, "localhost:8080", cfg.String("host-url"))
+		require.Equal(t, "s3cret", cfg.String("db-password"))
+		require.Equal(t, "mongodb", cfg.String("database-type"))
+		require.Equal(t, []string{"a", "b"}, cfg.StringArray("cas-url"))
+		require.Equal(t, 5, cfg.Int("max-conns"))
+
+		v, ok := cfg.Get("db-password")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourcePrompt, v.Source)
+
+		v, ok = cfg.Get("host-url")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceFlag, v.Source)
+
+		require.Equal(t, []string{
+			"db-password (The database password): ",
+			"database-type [mem, mongodb]: ",
+			"cas-url: ",
+			"max-conns: ",
+		}, prompter.prompts)
+		require.Equal(t, []bool{true, false, false, false}, prompter.masked)
+	})
+
+	t.Run("invalid value prompted again", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "s3cret")
+		t.Setenv("TEST_CAS_URL", "a")
+		t.Setenv("TEST_MAX_CONNS", "5")
+
+		prompter := &mockPrompter{interactive: true, inputs: []string{"localhost", "", "oracle", "mem"}}
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)), cmd.WithPrompter(prompter))
+		require.NoError(t, err)
+		require.Equal(t, "localhost", cfg.String("host-url"))
+		require.Equal(t, "mem", cfg.String("database-type"))
+
+		require.Len(t, prompter.invalid, 2)
+		require.EqualError(t, prompter.invalid[0], "a value for database-type is required")
+		require.Contains(t, prompter.invalid[1].Error(), "oracle")
+	})
+
+	t.Run("too many invalid values", func(t *testing.T) {
+		t.Setenv("TEST_HOST_URL", "localhost")
+		t.Setenv("TEST_DB_PASSWORD", "s3cret")
+		t.Setenv("TEST_DATABASE_TYPE", "mem")
+		t.Setenv("TEST_CAS_URL", "a")
+
+		prompter := &mockPrompter{interactive: true, inputs: []string{"1000", "x", "0"}}
+
+		_, err := r.Resolve(newCommand(t, registryFlags(r)), cmd.WithPrompter(prompter))
+		require.EqualError(t, err, "invalid configuration: invalid value for max-conns [0]: must be at least 1")
+		require.Len(t, prompter.invalid, cmd.MaxPromptAttempts-1)
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		t.Setenv("TEST_HOST_URL", "localhost")
+		t.Setenv("TEST_DATABASE_TYPE", "mem")
+		t.Setenv("TEST_CAS_URL", "a")
+		t.Setenv("TEST_MAX_CONNS", "5")
+
+		_, err := r.Resolve(newCommand(t, registryFlags(r)), cmd.WithPrompter(&mockPrompter{interactive: true}))
+		require.EqualError(t, err, "invalid configuration: read value for db-password: EOF")
+	})
+
+	t.Run("typed values taken literally", func(t *testing.T) {
+		typed := cmd.NewRegistry()
+
+		require.NoError(t, typed.Register(
+			&cmd.Parameter{FlagName: "token", EnvKey: "TEST_TOKEN", Required: true, AllowFile: true, AllowExec: true},
+			&cmd.Parameter{FlagName: "key", EnvKey: "TEST_KEY", Required: true, AllowFile: true},
+			&cmd.Parameter{FlagName: "debug", EnvKey: "TEST_DEBUG", Type: cmd.BoolType, Required: true},
+			&cmd.Parameter{FlagName: "ratio", EnvKey: "TEST_RATIO", Type: cmd.FloatType, Required: true},
+			&cmd.Parameter{FlagName: "timeout", EnvKey: "TEST_TIMEOUT", Type: cmd.DurationType, Required: true},
+			&cmd.Parameter{FlagName: "labels", EnvKey: "TEST_LABELS", Type: cmd.StringMapType, Required: true},
+			&cmd.Parameter{FlagName: "urls", EnvKey: "TEST_URLS", Type: cmd.StringArrayType, Required: true},
+		))
+
+		prompter := &mockPrompter{interactive: true, inputs: []string{
+			"exec:/bin/false", "@/nonexistent", "yes", "true", "0.5", "1d", "a=1,b=2", "x, ,y",
+		}}
+
+		command := newCommand(t, registryFlags(typed))
+
+		cfg, err := typed.Resolve(command, cmd.WithPrompter(prompter))
+		require.NoError(t, err)
+		require.Equal(t, "exec:/bin/false", cfg.String("token"))
+		require.Equal(t, "@/nonexistent", cfg.String("key"))
+		require.True(t, cfg.Bool("debug"))
+		require.Equal(t, 0.5, cfg.Float("ratio"))
+		require.Equal(t, 24*time.Hour, cfg.Duration("timeout"))
+		require.Equal(t, map[string]string{"a": "1", "b": "2"}, cfg.StringMap("labels"))
+		require.Equal(t, []string{"x", "y"}, cfg.StringArray("urls"))
+
+		require.Len(t, prompter.invalid, 1)
+		require.EqualError(t, prompter.invalid[0], `invalid value for debug [yes]: strconv.ParseBool: `+
+			`parsing "yes": invalid syntax`)
+
+		for _, name := range []string{"token", "key", "debug", "labels", "urls"} {
+			require.False(t, command.Flags().Changed(name), name)
+		}
+	})
+
+	t.Run("invalid secret not echoed", func(t *testing.T) {
+		minimum := 1.0
+
+		secrets := cmd.NewRegistry()
+
+		require.NoError(t, secrets.Register(
+			&cmd.Parameter{
+				FlagName: "pin", EnvKey: "TEST_PIN", Type: cmd.IntType, Required: true, Secret: true, Minimum: &minimum,
+			},
+			&cmd.Parameter{
+				FlagName: "level", EnvKey: "TEST_LEVEL", Required: true, Secret: true, Enum: []string{"low", "high"},
+			},
+		))
+
+		out := &bytes.Buffer{}
+		terminal := cmd.NewTerminalPrompter(os.Stdin, out)
+
+		prompter := &echoingPrompter{
+			mockPrompter: mockPrompter{interactive: true, inputs: []string{"s3cret-pin", "-4242", "7", "s3cret-level",
+				"high"}},
+			terminal: terminal,
+		}
+
+		cfg, err := secrets.Resolve(newCommand(t, registryFlags(secrets)), cmd.WithPrompter(prompter))
+		require.NoError(t, err)
+		require.Equal(t, 7, cfg.Int("pin"))
+		require.Equal(t, "high", cfg.String("level"))
+		require.Equal(t, []bool{true, true, true, true, true}, prompter.masked)
+
+		require.Len(t, prompter.invalid, 3)
+		require.Equal(t, "Invalid value: invalid value for pin: expecting a value of type int\n"+
+			"Invalid value: invalid value for pin: must be at least 1\n"+
+			"Invalid value: invalid value for level: allowed values are low, high\n", out.String())
+		require.NotContains(t, out.String(), "s3cret")
+		require.NotContains(t, out.String(), "4242")
+	})
+
+	t.Run("not interactive", func(t *testing.T) {
+		t.Setenv("TEST_HOST_URL", "localhost")
+		t.Setenv("TEST_DATABASE_TYPE", "mem")
+		t.Setenv("TEST_CAS_URL", "a")
+		t.Setenv("TEST_MAX_CONNS", "5")
+
+		prompter := &mockPrompter{inputs: []string{"s3cret"}}
+
+		_, err := r.Resolve(newCommand(t, registryFlags(r)), cmd.WithPrompter(prompter))
+		require.EqualError(t, err, "invalid configuration: Neither db-password (command line flag) nor "+
+			"TEST_DB_PASSWORD (environment variable) have been set.")
+		require.Empty(t, prompter.prompts)
+	})
+}
+
+func TestTerminalPrompter(t *testing.T) {
+	in, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, in.Close())
+	})
+
+	_, err = io.WriteString(w, "localhost:8080\r\ns3cret")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	out := &bytes.Buffer{}
+	prompter := cmd.NewTerminalPrompter(in, out)
+
+	require.False(t, prompter.Interactive())
+
+	value, err := prompter.Prompt("host-url: ", false)
+	require.NoError(t, err)
+	require.Equal(t, "localhost:8080", value)
+
+	value, err = prompter.Prompt("db-password: ", true)
+	require.NoError(t, err)
+	require.Equal(t, "s3cret", value)
+
+	_, err = prompter.Prompt("other: ", false)
+	require.ErrorIs(t, err, io.EOF)
+
+	prompter.Invalid(errors.New("invalid URL"))
+
+	require.Equal(t, "host-url: db-password: other: Invalid value: invalid URL\n", out.String())
+
+	cfg, err := newPromptRegistry(t).Resolve(&cobra.Command{Use: "start"}, cmd.WithPrompt())
+	require.Error(t, err)
+	require.Nil(t, cfg)
+}
+
+func newPromptRegistry(t *testing.T) *cmd.Registry {
+	t.Helper()
+
+	minimum, maximum := 1.0, 100.0
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL", Required: true},
+		&cmd.Parameter{
+			FlagName: "db-password", EnvKey: "TEST_DB_PASSWORD", Description: "The database password.", Required: true,
+			Secret: true,
+		},
+		&cmd.Parameter{
+			FlagName: "database-type", EnvKey: "TEST_DATABASE_TYPE", Required: true,
+			Enum: []string{"mem", "mongodb"}, EnumAliases: map[string]string{"mongo": "mongodb"},
+		},
+		&cmd.Parameter{FlagName: "cas-url", EnvKey: "TEST_CAS_URL", Type: cmd.CSVType, Required: true},
+		&cmd.Parameter{
+			FlagName: "max-conns", EnvKey: "TEST_MAX_CONNS", Type: cmd.IntType, Required: true, Minimum: &minimum,
+			Maximum: &maximum,
+		},
+		&cmd.Parameter{FlagName: "log-level", EnvKey: "TEST_LOG_LEVEL"},
+	))
+
+	return r
+}
+
+type mockPrompter struct {
+	interactive bool
+	inputs      []string
+	prompts     []string
+	masked      []bool
+	invalid     []error
+}
+
+func (m *mockPrompter) Interactive() bool {
+	return m.interactive
+}
+
+func (m *mockPrompter) Prompt(prompt string, masked bool) (string, error) {
+	m.prompts = append(m.prompts, prompt)
+	m.masked = append(m.masked, masked)
+
+	if len(m.inputs) == 0 {
+		return "", io.EOF
+	}
+
+	input := m.inputs[0]
+	m.inputs = m.inputs[1:]
+
+	return input, nil
+}
+
+func (m *mockPrompter) Invalid(err error) {
+	m.invalid = append(m.invalid, err)
+}
+
+// echoingPrompter reports invalid values with a terminal prompter.
+type echoingPrompter struct {
+	mockPrompter
+	terminal *cmd.TerminalPrompter
+}
+
+func (e *echoingPrompter) Invalid(err error) {
+	e.mockPrompter.Invalid(err)
+	e.terminal.Invalid(err)
+}
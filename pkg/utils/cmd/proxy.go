@@ -0,0 +1,408 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	tlsutil "github.com/trustbloc/cmdutil-go/pkg/utils/tls"
+)
+
+// Names of the proxy parameters. The flag names and environment variables of a ProxyGroup are prefixed
+// with the group prefix (e.g. "proxy-url" and "ORB_PROXY_URL").
+const (
+	ProxyURLName            = "url"
+	ProxyNoProxyName        = "no-proxy"
+	ProxyCACertsName        = "cacerts"
+	ProxySystemCertPoolName = "systemcertpool"
+)
+
+// Standard proxy environment variables which are used when the proxy parameters of a ProxyGroup aren't set.
+// The lower case variants are also supported.
+const (
+	HTTPProxyEnvKey  = "HTTP_PROXY"
+	HTTPSProxyEnvKey = "HTTPS_PROXY"
+	NoProxyEnvKey    = "NO_PROXY"
+)
+
+// ProxyParameters contains the outbound proxy parameters resolved by a ProxyGroup.
+type ProxyParameters struct {
+	// HTTPProxy is the proxy of plain HTTP requests (nil if requests are sent directly).
+	HTTPProxy *url.URL
+	// HTTPSProxy is the proxy of HTTPS requests (nil if requests are sent directly).
+	HTTPSProxy *url.URL
+	// ProxySource is the source of the proxy URLs: a flag or environment variable of the group, or the
+	// standard HTTP_PROXY and HTTPS_PROXY environment variables (SourceEnv).
+	ProxySource Source
+	// NoProxy contains the hosts which are accessed directly: "*" (all hosts), IP addresses, CIDRs (e.g.
+	// "10.0.0.0/8"), domains which also match their subdomains (e.g. "example.com" or ".example.com") and
+	// wildcards matching subdomains only (e.g. "*.example.com"). Domains and IP addresses may include a port.
+	NoProxy []string
+	// CACerts are the CA certs used to verify the proxy (and the hosts accessed through it).
+	CACerts []string
+	// SystemCertPool indicates whether the system cert pool is added to the CA certs.
+	SystemCertPool bool
+}
+
+// Enabled returns true if a proxy is configured.
+func (p *ProxyParameters) Enabled() bool {
+	return p.HTTPProxy != nil || p.HTTPSProxy != nil
+}
+
+// ProxyFunc returns a function to be used as the Proxy of an http.Transport. Requests to the loopback
+// interface and to the hosts of the no-proxy list are sent directly.
+func (p *ProxyParameters) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	matchers := make([]noProxyMatcher, 0, len(p.NoProxy))
+
+	for _, entry := range p.NoProxy {
+		//nolint:errcheck // the entries were validated when the parameters were resolved
+		m, _ := parseNoProxy(entry)
+		matchers = append(matchers, m)
+	}
+
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := p.HTTPProxy
+		if req.URL.Scheme == "https" {
+			proxy = p.HTTPSProxy
+		}
+
+		if proxy == nil || !useProxy(req.URL, matchers) {
+			return nil, nil
+		}
+
+		return proxy, nil
+	}
+}
+
+// TLSConfig returns the TLS configuration of the connections to the proxy and to the hosts accessed through
+// it. Nil is returned if no CA certs are configured, in which case the default TLS configuration applies.
+func (p *ProxyParameters) TLSConfig() (*tls.Config, error) {
+	if len(p.CACerts) == 0 {
+		return nil, nil
+	}
+
+	pool, err := tlsutil.GetCertPool(p.SystemCertPool, p.CACerts)
+	if err != nil {
+		return nil, fmt.Errorf("load proxy CA certs: %w", err)
+	}
+
+	return &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}, nil
+}
+
+// Transport returns a clone of http.DefaultTransport configured with the proxy function and TLS settings.
+func (p *ProxyParameters) Transport() (*http.Transport, error) {
+	tlsConfig, err := p.TLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	//nolint:forcetypeassert // http.DefaultTransport is always an *http.Transport
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = p.ProxyFunc()
+
+	if tlsConfig != nil {
+		t.TLSClientConfig = tlsConfig
+	}
+
+	return t, nil
+}
+
+// String returns a description of the proxy parameters in which the proxy passwords are redacted.
+func (p *ProxyParameters) String() string {
+	redacted := func(u *url.URL) string {
+		if u == nil {
+			return ""
+		}
+
+		return u.Redacted()
+	}
+
+	return fmt.Sprintf("httpProxy=%s httpsProxy=%s source=%s noProxy=%s caCerts=%s systemCertPool=%t",
+		redacted(p.HTTPProxy), redacted(p.HTTPSProxy), p.ProxySource, strings.Join(p.NoProxy, ","),
+		strings.Join(p.CACerts, ","), p.SystemCertPool)
+}
+
+// ProxyGroup is the option group of the outbound proxy parameters. The proxy URL and no-proxy list of the group
+// take precedence over the standard HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables which are used
+// when they aren't set.
+type ProxyGroup struct {
+	prefix GroupPrefix
+}
+
+var _ OptionGroup[*ProxyParameters] = (*ProxyGroup)(nil)
+
+// NewProxyGroup returns a proxy option group with the given prefix (e.g. "proxy-" and "ORB_PROXY_").
+func NewProxyGroup(prefix GroupPrefix) *ProxyGroup {
+	return &ProxyGroup{prefix: prefix}
+}
+
+// Parameters returns the proxy parameters.
+func (g *ProxyGroup) Parameters() []*Parameter {
+	return []*Parameter{
+		g.prefix.parameter(ProxyURLName, &Parameter{
+			Description: "The URL of the proxy of outbound HTTP and HTTPS requests. Defaults to the " +
+				"HTTP_PROXY and HTTPS_PROXY environment variables.",
+			Secret: true,
+		}),
+		g.prefix.parameter(ProxyNoProxyName, &Parameter{
+			Description: "Comma-separated list of hosts which are accessed directly: IP addresses, CIDRs, " +
+				"domains (which also match their subdomains) and wildcards (e.g. *.example.com). \"*\" disables " +
+				"the proxy. Defaults to the NO_PROXY environment variable.",
+			Type: CSVType,
+		}),
+		g.prefix.parameter(ProxyCACertsName, &Parameter{
+			Description:    "Comma-separated list of the paths of the CA certs of the proxy.",
+			Type:           StringArrayType,
+			Completion:     CompleteFile,
+			FileExtensions: []string{ExtPEM, ExtCRT},
+		}),
+		g.prefix.parameter(ProxySystemCertPoolName, &Parameter{
+			Description: "Add the system certificate pool to the CA certs of the proxy.",
+			Type:        BoolType,
+			Default:     true,
+		}),
+	}
+}
+
+// AddFlags adds the proxy flags to the given command.
+func (g *ProxyGroup) AddFlags(cmd *cobra.Command) error {
+	return addGroupFlags(cmd, cmd.Flags(), g.Parameters())
+}
+
+// Resolve resolves the proxy parameters. Errors don't contain the credentials of the proxy URLs.
+func (g *ProxyGroup) Resolve(cmd *cobra.Command) (*ProxyParameters, error) {
+	cfg, err := resolveGroupConfig(cmd, g.Parameters())
+	if err != nil {
+		return nil, err
+	}
+
+	params := &ProxyParameters{
+		NoProxy:        cfg.StringArray(g.prefix.FlagName(ProxyNoProxyName)),
+		CACerts:        cfg.StringArray(g.prefix.FlagName(ProxyCACertsName)),
+		SystemCertPool: cfg.Bool(g.prefix.FlagName(ProxySystemCertPoolName)),
+	}
+
+	urlName := g.prefix.FlagName(ProxyURLName)
+
+	if v, _ := cfg.Get(urlName); v.Source != SourceDefault {
+		proxy, err := parseProxyURL(cfg.String(urlName))
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", urlName, err)
+		}
+
+		params.HTTPProxy, params.HTTPSProxy, params.ProxySource = proxy, proxy, v.Source
+	} else {
+		if params.HTTPProxy, err = proxyFromEnv(HTTPProxyEnvKey); err != nil {
+			return nil, err
+		}
+
+		if params.HTTPSProxy, err = proxyFromEnv(HTTPSProxyEnvKey); err != nil {
+			return nil, err
+		}
+
+		if params.Enabled() {
+			params.ProxySource = SourceEnv
+		}
+	}
+
+	noProxyName := g.prefix.FlagName(ProxyNoProxyName)
+
+	if v, _ := cfg.Get(noProxyName); v.Source == SourceDefault {
+		params.NoProxy = splitNoProxy(proxyEnv(NoProxyEnvKey))
+		noProxyName = NoProxyEnvKey
+	}
+
+	for _, entry := range params.NoProxy {
+		if _, err := parseNoProxy(entry); err != nil {
+			return nil, fmt.Errorf("invalid value for %s: %w", noProxyName, err)
+		}
+	}
+
+	return params, nil
+}
+
+// Validate verifies that the proxy CA certs can be parsed.
+func (g *ProxyGroup) Validate(params *ProxyParameters) error {
+	var errs []error
+
+	for _, caCert := range params.CACerts {
+		if _, err := tlsutil.ParseCACertFile(caCert); err != nil {
+			errs = append(errs, fmt.Errorf("%s (%s): CA cert %s: %w", g.prefix.FlagName(ProxyCACertsName),
+				g.prefix.EnvKey(ProxyCACertsName), caCert, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
+
+// proxyEnv returns the value of the given standard proxy environment variable or of its lower case variant.
+func proxyEnv(key string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return os.Getenv(strings.ToLower(key))
+}
+
+func proxyFromEnv(key string) (*url.URL, error) {
+	value := proxyEnv(key)
+	if value == "" {
+		return nil, nil
+	}
+
+	proxy, err := parseProxyURL(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	return proxy, nil
+}
+
+// parseProxyURL parses the given proxy URL. The scheme defaults to http as with the standard environment
+// variables. The returned error doesn't contain the URL since it may contain credentials.
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		// The parse error isn't wrapped since it may contain part of the credentials.
+		return nil, errors.New("parse proxy URL: invalid URL")
+	}
+
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme [%s], expecting http, https or socks5", u.Scheme)
+	}
+
+	if u.Host == "" {
+		return nil, errors.New("proxy URL must include a host")
+	}
+
+	return u, nil
+}
+
+func splitNoProxy(value string) []string {
+	var entries []string
+
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+// noProxyMatcher matches the host and port of a request URL with a no-proxy entry. An empty port of the
+// entry matches any port.
+type noProxyMatcher struct {
+	all      bool
+	cidr     *net.IPNet
+	ip       net.IP
+	domain   string
+	wildcard bool
+	port     string
+}
+
+func parseNoProxy(entry string) (noProxyMatcher, error) {
+	entry = strings.ToLower(strings.TrimSpace(entry))
+
+	if entry == "*" {
+		return noProxyMatcher{all: true}, nil
+	}
+
+	if strings.Contains(entry, "/") {
+		_, cidr, err := net.ParseCIDR(entry)
+		if err != nil {
+			return noProxyMatcher{}, fmt.Errorf("invalid CIDR [%s]", entry)
+		}
+
+		return noProxyMatcher{cidr: cidr}, nil
+	}
+
+	host, port := entry, ""
+	if h, p, err := net.SplitHostPort(entry); err == nil {
+		host, port = h, p
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return noProxyMatcher{ip: ip, port: port}, nil
+	}
+
+	m := noProxyMatcher{domain: host, port: port}
+
+	switch {
+	case strings.HasPrefix(host, "*."):
+		m.domain, m.wildcard = host[1:], true
+	case strings.HasPrefix(host, "."):
+		m.domain = host[1:]
+	}
+
+	if m.domain == "" || m.domain == "." || strings.Contains(m.domain, "*") {
+		return noProxyMatcher{}, fmt.Errorf("invalid no-proxy entry [%s]", entry)
+	}
+
+	return m, nil
+}
+
+func (m *noProxyMatcher) match(host, port string, ip net.IP) bool {
+	switch {
+	case m.all:
+		return true
+	case m.cidr != nil:
+		return ip != nil && m.cidr.Contains(ip)
+	case m.port != "" && m.port != port:
+		return false
+	case m.ip != nil:
+		return ip != nil && m.ip.Equal(ip)
+	case m.wildcard:
+		return strings.HasSuffix(host, m.domain)
+	default:
+		return host == m.domain || strings.HasSuffix(host, "."+m.domain)
+	}
+}
+
+func useProxy(u *url.URL, matchers []noProxyMatcher) bool {
+	host := strings.ToLower(u.Hostname())
+
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+
+	if host == "localhost" {
+		return false
+	}
+
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+
+	for i := range matchers {
+		if matchers[i].match(host, port, ip) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,353 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+)
+
+// ParameterType defines the type of the value of a configuration parameter.
+type ParameterType string
+
+// Supported parameter types.
+const (
+	// StringType is a string value retrieved with GetString.
+	StringType ParameterType = "string"
+	// StringArrayType is a string array retrieved with GetStringArray (repeated flags or CSV env var).
+	StringArrayType ParameterType = "stringArray"
+	// CSVType is a string slice retrieved with GetUserSetCSVVar (CSV flag or CSV env var).
+	CSVType ParameterType = "csv"
+	// StringMapType is a string map retrieved with GetStringMap (repeated key=value flags or comma-separated
+	// key=value env var).
+	StringMapType ParameterType = "stringMap"
+	// BoolType is a boolean value retrieved with GetBool.
+	BoolType ParameterType = "bool"
+	// IntType is an integer value retrieved with GetInt.
+	IntType ParameterType = "int"
+	// FloatType is a floating point value retrieved with GetFloat.
+	FloatType ParameterType = "float"
+	// DurationType is a duration value retrieved with GetDuration.
+	DurationType ParameterType = "duration"
+)
+
+// Parameter describes a configuration parameter which may be set via either command line flag
+// or environment variable.
+type Parameter struct {
+	// FlagName is the name of the command line flag.
+	FlagName string
+	// EnvKey is the name of the environment variable.
+	EnvKey string
+	// Type is the type of the value. Defaults to StringType.
+	Type ParameterType
+	// Description is a human readable description of the parameter.
+	Description string
+	// Default is the value used when the parameter isn't set. It must be of the Go type matching Type
+	// (string, []string, map[string]string, bool, int, float64 or time.Duration).
+	Default interface{}
+	// Required indicates that the parameter must be set.
+	Required bool
+	// Enum contains the allowed values of a string parameter (if any). Values are matched case-insensitively.
+	Enum []string
+	// EnumAliases maps aliases to the allowed values in Enum.
+	EnumAliases map[string]string
+	// Minimum is the minimum allowed value of a numeric parameter (if any).
+	Minimum *float64
+	// Maximum is the maximum allowed value of a numeric parameter (if any).
+	Maximum *float64
+	// DuplicateKeys defines how a string map parameter handles a key which is set more than once.
+	DuplicateKeys DuplicateKeyPolicy
+	// DurationBounds is the range of allowed values of a duration parameter (if any).
+	DurationBounds *DurationBounds
+	// Completion defines how the shell completes the value of the parameter.
+	Completion CompletionKind
+	// FileExtensions restricts file path completion (CompleteFile) to the given extensions (e.g. "pem").
+	FileExtensions []string
+	// Secret indicates that the value is sensitive and must be redacted in logs and output.
+	Secret bool
+	// FileEnvKey is the name of an environment variable containing the path of a file from which the value
+	// of a string parameter is loaded (e.g. "ORB_DB_PASSWORD_FILE") if neither the command line flag nor
+	// the environment variable is set.
+	FileEnvKey string
+	// AllowFile allows the value of a string, string array or CSV parameter to be loaded from a file with
+	// "@path" (or from standard input with "@-"). A literal value starting with "@" must then be escaped
+	// as "@@".
+	AllowFile bool
+	// AllowExec allows the value of a string, string array or CSV parameter to be obtained from a secret
+	// provider with "exec:" (e.g. "exec:/usr/bin/get-secret db-password"). See RunSecretProvider.
+	AllowExec bool
+	// AllowedSources restricts the sources via which the parameter may be set (SourceFlag, SourceEnv,
+	// SourceFile or SourceExec), e.g. SourceFile and SourceExec for a secret which must never be set on the
+	// command line. All sources are allowed if empty. Violations are handled according to the SourcePolicy
+	// of Registry.Resolve.
+	AllowedSources []Source
+	// Exec is the command line of the secret provider of a string parameter (e.g. "/usr/bin/get-secret
+	// db-password"). If the parameter isn't set, then its value is the output of the command. See
+	// RunSecretProvider.
+	Exec string
+	// Nested indicates that the value of the parameter is decoded with GetNested, so that the indexed
+	// (e.g. ISSUERS_0_NAME) and nested (e.g. DB__URL) environment variables derived from EnvKey aren't
+	// reported as unknown by FindUnknownEnvKeys and CheckEnv.
+	Nested bool
+	// Deprecated contains the deprecation notice of the parameter. An empty value means that the
+	// parameter isn't deprecated.
+	Deprecated string
+}
+
+// Registry holds the configuration parameters registered by an application.
+type Registry struct {
+	mutex  sync.RWMutex
+	params []*Parameter
+	byFlag map[string]*Parameter
+	byEnv  map[string]*Parameter
+}
+
+// NewRegistry returns a new, empty parameter registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byFlag: make(map[string]*Parameter),
+		byEnv:  make(map[string]*Parameter),
+	}
+}
+
+// Register adds the given parameters to the registry. An error is returned if a parameter is invalid
+// or if its flag name or environment variable is already registered.
+func (r *Registry) Register(params ...*Parameter) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, p := range params {
+		if err := validateParameter(p); err != nil {
+			return err
+		}
+
+		if _, ok := r.byFlag[p.FlagName]; ok {
+			return fmt.Errorf("parameter %s is already registered", p.FlagName)
+		}
+
+		if _, ok := r.byEnv[p.EnvKey]; ok {
+			return fmt.Errorf("environment variable %s is already registered", p.EnvKey)
+		}
+
+		if _, ok := r.byEnv[p.FileEnvKey]; ok && p.FileEnvKey != "" {
+			return fmt.Errorf("environment variable %s is already registered", p.FileEnvKey)
+		}
+
+		if p.Type == "" {
+			p.Type = StringType
+		}
+
+		r.params = append(r.params, p)
+		r.byFlag[p.FlagName] = p
+		r.byEnv[p.EnvKey] = p
+
+		if p.FileEnvKey != "" {
+			r.byEnv[p.FileEnvKey] = p
+		}
+	}
+
+	return nil
+}
+
+// Parameters returns the registered parameters in the order in which they were registered.
+func (r *Registry) Parameters() []*Parameter {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	params := make([]*Parameter, len(r.params))
+	copy(params, r.params)
+
+	return params
+}
+
+// Get returns the parameter registered with the given flag name.
+func (r *Registry) Get(flagName string) (*Parameter, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	p, ok := r.byFlag[flagName]
+
+	return p, ok
+}
+
+// GetByEnvKey returns the parameter registered with the given environment variable.
+func (r *Registry) GetByEnvKey(envKey string) (*Parameter, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	p, ok := r.byEnv[envKey]
+
+	return p, ok
+}
+
+// enum returns the Enum of the allowed values of the parameter or nil if the values aren't restricted.
+func (p *Parameter) enum() *Enum {
+	if len(p.Enum) == 0 {
+		return nil
+	}
+
+	e := NewEnum(p.Enum...)
+
+	for alias, value := range p.EnumAliases {
+		e.WithAlias(alias, value)
+	}
+
+	return e
+}
+
+func validateParameter(p *Parameter) error {
+	if p == nil {
+		return errors.New("parameter is nil")
+	}
+
+	if p.FlagName == "" {
+		return errors.New("parameter flag name is empty")
+	}
+
+	if p.EnvKey == "" {
+		return fmt.Errorf("environment variable for parameter %s is empty", p.FlagName)
+	}
+
+	switch p.Type {
+	case "", StringType, StringArrayType, CSVType, StringMapType, BoolType, IntType, FloatType, DurationType:
+	default:
+		return fmt.Errorf("unsupported type [%s] for parameter %s", p.Type, p.FlagName)
+	}
+
+	switch p.DuplicateKeys {
+	case DuplicateKeyError, DuplicateKeyFirst, DuplicateKeyLast:
+	default:
+		return fmt.Errorf("unsupported duplicate key policy [%s] for parameter %s", p.DuplicateKeys, p.FlagName)
+	}
+
+	switch p.Completion {
+	case CompleteDefault, CompleteFile, CompleteDir, CompleteNone:
+	default:
+		return fmt.Errorf("unsupported completion kind [%s] for parameter %s", p.Completion, p.FlagName)
+	}
+
+	if err := validateValueSources(p); err != nil {
+		return err
+	}
+
+	if p.Minimum != nil && p.Maximum != nil && *p.Minimum > *p.Maximum {
+		return fmt.Errorf("minimum is greater than maximum for parameter %s", p.FlagName)
+	}
+
+	if err := validateEnum(p); err != nil {
+		return err
+	}
+
+	if b := p.DurationBounds; b != nil && b.Max > 0 && b.Min > b.Max {
+		return fmt.Errorf("minimum duration is greater than maximum duration for parameter %s", p.FlagName)
+	}
+
+	return nil
+}
+
+func validateEnum(p *Parameter) error {
+	if len(p.Enum) > 0 && p.Type != "" && p.Type != StringType {
+		return fmt.Errorf("allowed values are only supported for string parameters: %s", p.FlagName)
+	}
+
+	for alias, value := range p.EnumAliases {
+		if _, ok := NewEnum(p.Enum...).Match(value); !ok {
+			return fmt.Errorf("alias %s of parameter %s refers to unknown value %s", alias, p.FlagName, value)
+		}
+	}
+
+	if len(p.Enum) == 0 || p.Default == nil {
+		return nil
+	}
+
+	if value, ok := p.Default.(string); !ok || !contains(p.Enum, value) {
+		return fmt.Errorf("default value [%v] of parameter %s isn't one of the allowed values %s", p.Default,
+			p.FlagName, strings.Join(p.Enum, ", "))
+	}
+
+	return nil
+}
+
+// isStringType returns true for the types of parameters whose values are strings or lists of strings.
+func isStringType(t ParameterType) bool {
+	switch t {
+	case "", StringType, StringArrayType, CSVType:
+		return true
+	default:
+		return false
+	}
+}
+
+// resolver returns the resolver of the raw values of the parameter. Values are only loaded from files or
+// obtained from secret providers (cached in the given cache) if the parameter allows it.
+func (p *Parameter) resolver(cache *execCache) valueResolver {
+	return func(cmd *cobra.Command, name, value string) (string, error) {
+		switch {
+		case p.AllowExec && IsExecValue(value):
+			return resolveExecValue(cmd, cache, name, value)
+		case p.AllowFile:
+			return resolveFileValue(cmd, name, value)
+		default:
+			return resolveValue(cmd, name, value)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateValueSources(p *Parameter) error {
+	if p.Exec != "" && p.Type != "" && p.Type != StringType {
+		return fmt.Errorf("a secret provider isn't supported for parameter %s of type %s", p.FlagName, p.Type)
+	}
+
+	if p.Exec != "" && len(p.Enum) > 0 {
+		return fmt.Errorf("a secret provider isn't supported for enum parameter %s", p.FlagName)
+	}
+
+	if p.FileEnvKey != "" && ((p.Type != "" && p.Type != StringType) || len(p.Enum) > 0) {
+		return fmt.Errorf("a file environment variable is only supported for string parameters: %s", p.FlagName)
+	}
+
+	if p.AllowFile && (!isStringType(p.Type) || len(p.Enum) > 0) {
+		return fmt.Errorf("loading the value from a file is only supported for string parameters: %s", p.FlagName)
+	}
+
+	if p.Nested && ((p.Type != "" && p.Type != StringType && p.Type != StringArrayType) || len(p.Enum) > 0) {
+		return fmt.Errorf("nested values are only supported for string and string array parameters: %s",
+			p.FlagName)
+	}
+
+	if p.AllowExec && (!isStringType(p.Type) || len(p.Enum) > 0) {
+		return fmt.Errorf("a secret provider is only supported for string parameters: %s", p.FlagName)
+	}
+
+	if p.FileEnvKey != "" && p.FileEnvKey == p.EnvKey {
+		return fmt.Errorf("the file environment variable of parameter %s must differ from %s", p.FlagName,
+			p.EnvKey)
+	}
+
+	for _, s := range p.AllowedSources {
+		switch s {
+		case SourceFlag, SourceEnv, SourceFile, SourceExec:
+		default:
+			return fmt.Errorf("unsupported allowed source [%s] for parameter %s", s, p.FlagName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegistry(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		r := cmd.NewRegistry()
+
+		require.NoError(t, r.Register(
+			&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL"},
+			&cmd.Parameter{FlagName: "timeout", EnvKey: "TEST_TIMEOUT", Type: cmd.DurationType},
+		))
+
+		params := r.Parameters()
+		require.Len(t, params, 2)
+		require.Equal(t, "host-url", params[0].FlagName)
+		require.Equal(t, cmd.StringType, params[0].Type)
+		require.Equal(t, "timeout", params[1].FlagName)
+
+		p, ok := r.Get("timeout")
+		require.True(t, ok)
+		require.Equal(t, cmd.DurationType, p.Type)
+
+		p, ok = r.GetByEnvKey("TEST_HOST_URL")
+		require.True(t, ok)
+		require.Equal(t, "host-url", p.FlagName)
+
+		_, ok = r.Get("unknown")
+		require.False(t, ok)
+	})
+
+	t.Run("duplicate parameter", func(t *testing.T) {
+		r := cmd.NewRegistry()
+
+		require.NoError(t, r.Register(&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL"}))
+
+		err := r.Register(&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_OTHER"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parameter host-url is already registered")
+
+		err = r.Register(&cmd.Parameter{FlagName: "other", EnvKey: "TEST_HOST_URL"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "environment variable TEST_HOST_URL is already registered")
+	})
+
+	t.Run("invalid parameter", func(t *testing.T) {
+		r := cmd.NewRegistry()
+
+		err := r.Register(nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parameter is nil")
+
+		err = r.Register(&cmd.Parameter{EnvKey: "TEST_HOST_URL"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "parameter flag name is empty")
+
+		err = r.Register(&cmd.Parameter{FlagName: "host-url"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "environment variable for parameter host-url is empty")
+
+		err = r.Register(&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL", Type: "complex"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported type [complex] for parameter host-url")
+
+		minimum := 10.0
+		maximum := 1.0
+
+		err = r.Register(&cmd.Parameter{
+			FlagName: "count", EnvKey: "TEST_COUNT", Type: cmd.IntType, Minimum: &minimum, Maximum: &maximum,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "minimum is greater than maximum for parameter count")
+
+		err = r.Register(&cmd.Parameter{FlagName: "count", EnvKey: "TEST_COUNT", Type: cmd.IntType, Exec: "echo 1"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a secret provider isn't supported for parameter count of type int")
+
+		err = r.Register(&cmd.Parameter{FlagName: "mode", EnvKey: "TEST_MODE", Enum: []string{"a"}, Exec: "echo a"})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "a secret provider isn't supported for enum parameter mode")
+	})
+}
@@ -0,0 +1,557 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/trustbloc/cmdutil-go/internal/logfields"
+)
+
+// RedactedValue replaces the values of secret parameters in logs and output.
+const RedactedValue = "[REDACTED]"
+
+// Source identifies where the value of a parameter was resolved from. A value loaded from a file or obtained
+// from a secret provider is identified as such whether it's referenced by a command line flag or by an
+// environment variable.
+type Source string
+
+// Parameter value sources.
+const (
+	// SourceFlag indicates that the value was set literally with a command line flag.
+	SourceFlag Source = "flag"
+	// SourceEnv indicates that the value was set literally with an environment variable.
+	SourceEnv Source = "env"
+	// SourceFile indicates that the value was loaded from a file referenced with "@path" (in a command line
+	// flag or environment variable of a parameter with Parameter.AllowFile) or with the Parameter.FileEnvKey
+	// environment variable.
+	SourceFile Source = "file"
+	// SourceExec indicates that the value was obtained from a secret provider, either with an "exec:" value
+	// (of a parameter with Parameter.AllowExec) or with Parameter.Exec.
+	SourceExec Source = "exec"
+	// SourcePrompt indicates that the value was entered by the user when prompted.
+	SourcePrompt Source = "prompt"
+	// SourceDefault indicates that the parameter wasn't set and the default value is used.
+	SourceDefault Source = "default"
+)
+
+// Value is the resolved value of a parameter.
+type Value struct {
+	Parameter *Parameter
+	Value     interface{}
+	Source    Source
+	// Secret indicates that the value is sensitive and must be redacted in logs and output: the parameter
+	// is Secret or the value was decrypted, obtained from a secret provider or loaded from a file.
+	Secret bool
+}
+
+// String returns the string representation of the value. Secret values are redacted.
+func (v *Value) String() string {
+	s := formatValue(v.Value)
+
+	if v.Secret && s != "" {
+		return RedactedValue
+	}
+
+	return s
+}
+
+// Config contains the resolved values of the registered parameters.
+type Config struct {
+	values     map[string]*Value
+	order      []string
+	tls        *TLSParameters
+	violations []*SourceViolation
+}
+
+// Get returns the resolved value of the parameter with the given flag name.
+func (c *Config) Get(flagName string) (*Value, bool) {
+	v, ok := c.values[flagName]
+
+	return v, ok
+}
+
+// Values returns the resolved values in the order in which the parameters were registered.
+func (c *Config) Values() []*Value {
+	values := make([]*Value, len(c.order))
+
+	for i, name := range c.order {
+		values[i] = c.values[name]
+	}
+
+	return values
+}
+
+// String returns the value of the given string parameter or an empty string if the
+// parameter isn't resolved.
+func (c *Config) String(flagName string) string {
+	v, _ := c.value(flagName).(string) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// StringArray returns the value of the given string array or CSV parameter or nil if the
+// parameter isn't resolved.
+func (c *Config) StringArray(flagName string) []string {
+	v, _ := c.value(flagName).([]string) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// StringMap returns the value of the given string map parameter or nil if the parameter isn't resolved.
+func (c *Config) StringMap(flagName string) map[string]string {
+	v, _ := c.value(flagName).(map[string]string) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Bool returns the value of the given boolean parameter or false if the parameter isn't resolved.
+func (c *Config) Bool(flagName string) bool {
+	v, _ := c.value(flagName).(bool) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Int returns the value of the given integer parameter or 0 if the parameter isn't resolved.
+func (c *Config) Int(flagName string) int {
+	v, _ := c.value(flagName).(int) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Float returns the value of the given floating point parameter or 0 if the parameter isn't resolved.
+func (c *Config) Float(flagName string) float64 {
+	v, _ := c.value(flagName).(float64) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Duration returns the value of the given duration parameter or 0 if the parameter isn't resolved.
+func (c *Config) Duration(flagName string) time.Duration {
+	v, _ := c.value(flagName).(time.Duration) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// SourceViolations returns the parameters which were set via a source which isn't allowed when the
+// configuration was resolved with SourcePolicyWarn.
+func (c *Config) SourceViolations() []*SourceViolation {
+	return c.violations
+}
+
+// TLS returns the TLS parameters resolved with the WithTLS option or nil if the option wasn't provided.
+func (c *Config) TLS() *TLSParameters {
+	return c.tls
+}
+
+func (c *Config) value(flagName string) interface{} {
+	v, ok := c.values[flagName]
+	if !ok {
+		return nil
+	}
+
+	return v.Value
+}
+
+// ValidationError contains all of the errors encountered while resolving the configuration.
+type ValidationError struct {
+	Errors []error
+}
+
+// Error returns the error message.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return "invalid configuration: " + strings.Join(msgs, "; ")
+}
+
+// Is returns true if any of the errors contained in the validation error matches the target. It allows
+// errors.Is to find the contained errors (Unwrap() []error is only supported from Go 1.20).
+func (e *ValidationError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// As finds the first of the errors contained in the validation error which matches the target and sets the
+// target to that error. It allows errors.As to find the contained errors.
+func (e *ValidationError) As(target interface{}) bool {
+	for _, err := range e.Errors {
+		if errors.As(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type resolveOptions struct {
+	tlsFields    *TLSFields
+	tlsOptions   []TLSOption
+	sourcePolicy SourcePolicy
+	prompter     Prompter
+}
+
+// ResolveOption is an option for resolving the configuration.
+type ResolveOption func(opts *resolveOptions)
+
+// WithTLS resolves the TLS parameters with the given fields and options using GetTLS.
+func WithTLS(tlsFields *TLSFields, tlsOpts ...TLSOption) ResolveOption {
+	return func(opts *resolveOptions) {
+		opts.tlsFields = tlsFields
+		opts.tlsOptions = append(opts.tlsOptions, tlsOpts...)
+	}
+}
+
+// AddFlags adds a command line flag for each registered parameter to the given command.
+func (r *Registry) AddFlags(cmd *cobra.Command) {
+	r.addFlags(cmd, cmd.Flags())
+}
+
+// AddPersistentFlags adds a persistent command line flag for each registered parameter to the given command
+// so that the parameters are also available to its sub-commands.
+func (r *Registry) AddPersistentFlags(cmd *cobra.Command) {
+	r.addFlags(cmd, cmd.PersistentFlags())
+}
+
+func (r *Registry) addFlags(cmd *cobra.Command, flags *pflag.FlagSet) {
+	for _, p := range r.Parameters() {
+		if flags.Lookup(p.FlagName) != nil {
+			continue
+		}
+
+		description := p.Description
+
+		enum := p.enum()
+		if enum != nil {
+			description = enum.Usage(description)
+		}
+
+		usage := strings.TrimSpace(description + " Alternatively, this can be set with the following " +
+			"environment variable: " + p.EnvKey)
+
+		switch p.Type {
+		case StringArrayType, StringMapType:
+			flags.StringArray(p.FlagName, nil, usage)
+		case CSVType:
+			flags.StringSlice(p.FlagName, nil, usage)
+		default:
+			flags.String(p.FlagName, "", usage)
+		}
+
+		if p.Deprecated != "" {
+			//nolint:errcheck // the flag was just added so the error will not happen
+			_ = flags.MarkDeprecated(p.FlagName, p.Deprecated)
+		}
+
+		//nolint:errcheck // the flag was just added and the completion kind was validated on registration
+		_ = registerCompletion(cmd, p)
+	}
+}
+
+// Resolve resolves and validates all of the registered parameters from the command line flags and environment
+// variables of the given command. All validation errors are returned together in a ValidationError.
+func (r *Registry) Resolve(cmd *cobra.Command, opts ...ResolveOption) (*Config, error) {
+	options := &resolveOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	cfg := &Config{
+		values: make(map[string]*Value),
+	}
+
+	var errs []error
+
+	// Each secret provider command runs once per resolution.
+	cache := newExecCache()
+
+	for _, p := range r.Parameters() {
+		if violation := checkSource(cmd, p); violation != nil {
+			if options.sourcePolicy != SourcePolicyWarn {
+				errs = append(errs, violation)
+
+				continue
+			}
+
+			logSourceViolation(violation)
+
+			cfg.violations = append(cfg.violations, violation)
+		}
+
+		var (
+			v   *Value
+			err error
+		)
+
+		if isPromptable(cmd, p, options.prompter) {
+			v, err = promptParameter(p, options.prompter)
+		} else {
+			v, err = resolveParameter(cmd, p, cache)
+		}
+
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		if _, key := sourceOf(cmd, p); p.Deprecated != "" && key == p.EnvKey {
+			logger.Warn("Deprecated environment variable is set: "+p.Deprecated, logfields.WithConfigKey(p.EnvKey))
+		}
+
+		cfg.values[p.FlagName] = v
+		cfg.order = append(cfg.order, p.FlagName)
+	}
+
+	if options.tlsFields != nil {
+		tlsParams, err := GetTLS(cmd, options.tlsFields, options.tlsOptions...)
+
+		var validationErr *ValidationError
+
+		switch {
+		case errors.As(err, &validationErr):
+			errs = append(errs, validationErr.Errors...)
+		case err != nil:
+			errs = append(errs, err)
+		default:
+			cfg.tls = tlsParams
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return cfg, nil
+}
+
+// PreRunE returns a function, suitable for cobra.Command's PreRunE or PersistentPreRunE, which resolves
+// the registered parameters and stores the resulting Config in the context of the command. The Config
+// may then be retrieved with ConfigFromContext.
+func (r *Registry) PreRunE(opts ...ResolveOption) func(cmd *cobra.Command, args []string) error {
+	return func(cmd *cobra.Command, args []string) error {
+		cfg, err := r.Resolve(cmd, opts...)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		if ctx == nil {
+			ctx = context.Background()
+		}
+
+		cmd.SetContext(ContextWithConfig(ctx, cfg))
+
+		return nil
+	}
+}
+
+// Attach adds the registered parameters as persistent flags of the given command and sets its
+// PersistentPreRunE to resolve the configuration. An existing PersistentPreRunE is invoked
+// after the configuration has been resolved.
+func (r *Registry) Attach(cmd *cobra.Command, opts ...ResolveOption) {
+	r.AddPersistentFlags(cmd)
+
+	preRunE := r.PreRunE(opts...)
+	next := cmd.PersistentPreRunE
+
+	cmd.PersistentPreRunE = func(c *cobra.Command, args []string) error {
+		if err := preRunE(c, args); err != nil {
+			return err
+		}
+
+		if next != nil {
+			return next(c, args)
+		}
+
+		return nil
+	}
+}
+
+type configKey struct{}
+
+// ContextWithConfig returns a copy of the given context which carries the given Config.
+func ContextWithConfig(ctx context.Context, cfg *Config) context.Context {
+	return context.WithValue(ctx, configKey{}, cfg)
+}
+
+// ConfigFromContext returns the Config stored in the given context.
+func ConfigFromContext(ctx context.Context) (*Config, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+
+	cfg, ok := ctx.Value(configKey{}).(*Config)
+
+	return cfg, ok
+}
+
+func resolveParameter(cmd *cobra.Command, p *Parameter, cache *execCache) (*Value, error) {
+	isOptional := !p.Required
+
+	var (
+		value interface{}
+		err   error
+	)
+
+	switch p.Type {
+	case StringArrayType, CSVType:
+		value, err = resolveStringArray(cmd, p, isOptional, cache)
+	case StringMapType:
+		value, err = resolveStringMap(cmd, p, isOptional)
+	case BoolType:
+		value, err = GetBool(cmd, p.FlagName, p.EnvKey, defaultOf(p, false), isOptional)
+	case IntType:
+		value, err = GetInt(cmd, p.FlagName, p.EnvKey, defaultOf(p, 0), isOptional)
+	case FloatType:
+		value, err = GetFloat(cmd, p.FlagName, p.EnvKey, defaultOf(p, 0.0), isOptional)
+	case DurationType:
+		value, err = resolveDuration(cmd, p, isOptional)
+	default:
+		value, err = resolveString(cmd, p, isOptional, cache)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateValue(p, value); err != nil {
+		return nil, err
+	}
+
+	source, _ := sourceOf(cmd, p)
+
+	return &Value{
+		Parameter: p,
+		Value:     value,
+		Source:    source,
+		Secret:    isSecretValue(cmd, p, source),
+	}, nil
+}
+
+func resolveDuration(cmd *cobra.Command, p *Parameter, isOptional bool) (time.Duration, error) {
+	return getDuration(cmd, p.FlagName, p.EnvKey, defaultOf(p, time.Duration(0)), p.DurationBounds, isOptional)
+}
+
+func resolveString(cmd *cobra.Command, p *Parameter, isOptional bool, cache *execCache) (string, error) {
+	if enum := p.enum(); enum != nil {
+		return GetEnum(cmd, p.FlagName, p.EnvKey, enum, defaultOf(p, ""), isOptional)
+	}
+
+	value, err := getString(cmd, p.FlagName, p.EnvKey, isOptional || p.FileEnvKey != "" || p.Exec != "", p.resolver(cache))
+	if err != nil {
+		return "", err
+	}
+
+	if path := os.Getenv(p.FileEnvKey); value == "" && p.FileEnvKey != "" && path != "" {
+		return resolveFileValue(cmd, p.FileEnvKey, FileValuePrefix+path)
+	}
+
+	if value == "" && p.Exec != "" {
+		return execValue(cmd, cache, p.FlagName, ExecValuePrefix+p.Exec)
+	}
+
+	if value == "" && !isOptional && p.FileEnvKey != "" {
+		return "", errors.New("Neither " + p.FlagName + " (command line flag) nor " + p.EnvKey + " or " +
+			p.FileEnvKey + " (environment variables) have been set.")
+	}
+
+	if value == "" {
+		return defaultOf(p, ""), nil
+	}
+
+	return value, nil
+}
+
+func resolveStringArray(cmd *cobra.Command, p *Parameter, isOptional bool, cache *execCache) ([]string, error) {
+	var (
+		value []string
+		err   error
+	)
+
+	if p.Type == CSVType {
+		value, err = getCSV(cmd, p.FlagName, p.EnvKey, isOptional, p.resolver(cache))
+	} else {
+		value, err = getStringArray(cmd, p.FlagName, p.EnvKey, isOptional, p.resolver(cache))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) == 0 {
+		return defaultOf(p, value), nil
+	}
+
+	return value, nil
+}
+
+func resolveStringMap(cmd *cobra.Command, p *Parameter, isOptional bool) (map[string]string, error) {
+	value, err := GetStringMap(cmd, p.FlagName, p.EnvKey, p.DuplicateKeys, isOptional)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(value) == 0 {
+		return defaultOf(p, value), nil
+	}
+
+	return value, nil
+}
+
+func defaultOf[T any](p *Parameter, zero T) T {
+	if v, ok := p.Default.(T); ok {
+		return v
+	}
+
+	return zero
+}
+
+func validateValue(p *Parameter, value interface{}) error {
+	var n float64
+
+	switch v := value.(type) {
+	case int:
+		n = float64(v)
+	case float64:
+		n = v
+	default:
+		return nil
+	}
+
+	name := fmt.Sprintf("%s [%v]", p.FlagName, value)
+	if p.Secret {
+		name = p.FlagName
+	}
+
+	if p.Minimum != nil && n < *p.Minimum {
+		return fmt.Errorf("invalid value for %s: must be at least %v", name, *p.Minimum)
+	}
+
+	if p.Maximum != nil && n > *p.Maximum {
+		return fmt.Errorf("invalid value for %s: must be at most %v", name, *p.Maximum)
+	}
+
+	return nil
+}
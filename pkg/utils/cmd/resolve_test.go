@@ -0,0 +1,291 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegistry_Resolve(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		r := newResolveRegistry(t)
+
+		command := &cobra.Command{
+			Use: "start",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return nil
+			},
+		}
+
+		r.AddFlags(command)
+
+		t.Setenv("TEST_MAX_CONNECTIONS", "20")
+		t.Setenv("TEST_CAS_URL", "https://cas1,https://cas2")
+
+		command.SetArgs([]string{"--host-url", "localhost:8080", "--timeout", "1m"})
+		require.NoError(t, command.Execute())
+
+		cfg, err := r.Resolve(command)
+		require.NoError(t, err)
+
+		require.Equal(t, "localhost:8080", cfg.String("host-url"))
+		require.Equal(t, "mem", cfg.String("database-type"))
+		require.Equal(t, []string{"https://cas1", "https://cas2"}, cfg.StringArray("cas-url"))
+		require.Equal(t, 20, cfg.Int("max-connections"))
+		require.Equal(t, 0.5, cfg.Float("ratio"))
+		require.True(t, cfg.Bool("enable-metrics"))
+		require.Equal(t, time.Minute, cfg.Duration("timeout"))
+		require.Nil(t, cfg.TLS())
+
+		require.Empty(t, cfg.String("unknown"))
+		require.Zero(t, cfg.Int("host-url"))
+
+		v, ok := cfg.Get("host-url")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceFlag, v.Source)
+
+		v, ok = cfg.Get("max-connections")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceEnv, v.Source)
+
+		v, ok = cfg.Get("database-type")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceDefault, v.Source)
+
+		values := cfg.Values()
+		require.Len(t, values, 7)
+		require.Equal(t, "host-url", values[0].Parameter.FlagName)
+	})
+
+	t.Run("validation errors", func(t *testing.T) {
+		r := newResolveRegistry(t)
+
+		command := &cobra.Command{Use: "start"}
+
+		t.Setenv("TEST_DATABASE_TYPE", "couchdb")
+		t.Setenv("TEST_MAX_CONNECTIONS", "200")
+		t.Setenv("TEST_RATIO", "-1")
+		t.Setenv("TEST_TIMEOUT", "xxx")
+
+		cfg, err := r.Resolve(command)
+		require.Error(t, err)
+		require.Nil(t, cfg)
+
+		var validationErr *cmd.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		require.Len(t, validationErr.Errors, 5)
+
+		require.Contains(t, err.Error(), "Neither host-url (command line flag) nor TEST_HOST_URL")
+		require.Contains(t, err.Error(), "invalid value for database-type [couchdb]: allowed values are mem, mongodb")
+		require.Contains(t, err.Error(), "invalid value for max-connections [200]: must be at most 100")
+		require.Contains(t, err.Error(), "invalid value for ratio [-1]: must be at least 0")
+		require.Contains(t, err.Error(), "invalid value for timeout (TEST_TIMEOUT) [xxx]")
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		r := cmd.NewRegistry()
+
+		command := &cobra.Command{Use: "start"}
+
+		t.Setenv("TEST_TLS_SYSTEMCERTPOOL", "true")
+		t.Setenv("TEST_TLS_CACERTS", "ca1.pem,ca2.pem")
+
+		cfg, err := r.Resolve(command, cmd.WithTLS(newTestTLSFields()))
+		require.NoError(t, err)
+		require.NotNil(t, cfg.TLS())
+		require.True(t, cfg.TLS().SystemCertPool)
+		require.Equal(t, []string{"ca1.pem", "ca2.pem"}, cfg.TLS().CACerts)
+
+		t.Setenv("TEST_TLS_SYSTEMCERTPOOL", "xxx")
+
+		_, err = r.Resolve(command, cmd.WithTLS(newTestTLSFields()))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "tls-systemcertpool")
+	})
+}
+
+func TestRegistry_Attach(t *testing.T) {
+	r := newResolveRegistry(t)
+
+	var (
+		cfg         *cmd.Config
+		nextInvoked bool
+	)
+
+	root := &cobra.Command{
+		Use: "root",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			nextInvoked = true
+
+			return nil
+		},
+	}
+
+	start := &cobra.Command{
+		Use: "start",
+		RunE: func(c *cobra.Command, args []string) error {
+			var ok bool
+
+			cfg, ok = cmd.ConfigFromContext(c.Context())
+			require.True(t, ok)
+
+			return nil
+		},
+	}
+
+	root.AddCommand(start)
+
+	r.Attach(root)
+
+	t.Run("success", func(t *testing.T) {
+		root.SetArgs([]string{"start", "--host-url", "localhost:8080"})
+		require.NoError(t, root.ExecuteContext(context.Background()))
+
+		require.True(t, nextInvoked)
+		require.NotNil(t, cfg)
+		require.Equal(t, "localhost:8080", cfg.String("host-url"))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		nextInvoked = false
+
+		t.Setenv("TEST_MAX_CONNECTIONS", "xxx")
+
+		root.SetArgs([]string{"start", "--host-url", "localhost:8080"})
+		err := root.Execute()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for max-connections [xxx]")
+		require.False(t, nextInvoked)
+	})
+}
+
+func TestConfigFromContext(t *testing.T) {
+	cfg, ok := cmd.ConfigFromContext(context.Background())
+	require.False(t, ok)
+	require.Nil(t, cfg)
+
+	cfg, ok = cmd.ConfigFromContext(nil) //nolint:staticcheck // testing nil context
+	require.False(t, ok)
+	require.Nil(t, cfg)
+}
+
+func newResolveRegistry(t *testing.T) *cmd.Registry {
+	t.Helper()
+
+	minimum := 0.0
+	maximum := 100.0
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL", Required: true},
+		&cmd.Parameter{
+			FlagName: "database-type", EnvKey: "TEST_DATABASE_TYPE", Enum: []string{"mem", "mongodb"}, Default: "mem",
+		},
+		&cmd.Parameter{FlagName: "cas-url", EnvKey: "TEST_CAS_URL", Type: cmd.StringArrayType},
+		&cmd.Parameter{
+			FlagName: "max-connections", EnvKey: "TEST_MAX_CONNECTIONS", Type: cmd.IntType, Maximum: &maximum,
+		},
+		&cmd.Parameter{FlagName: "ratio", EnvKey: "TEST_RATIO", Type: cmd.FloatType, Default: 0.5, Minimum: &minimum},
+		&cmd.Parameter{FlagName: "enable-metrics", EnvKey: "TEST_ENABLE_METRICS", Type: cmd.BoolType, Default: true},
+		&cmd.Parameter{FlagName: "timeout", EnvKey: "TEST_TIMEOUT", Type: cmd.DurationType, Default: time.Second},
+	))
+
+	return r
+}
+
+func TestValue_Secret(t *testing.T) {
+	key, err := cmd.GenerateEncryptionKey()
+	require.NoError(t, err)
+
+	encrypted, err := cmd.EncryptValue(key, "s3cret")
+	require.NoError(t, err)
+
+	t.Setenv(cmd.EncryptionKeyEnvKey, base64.StdEncoding.EncodeToString(key))
+
+	file := filepath.Join(t.TempDir(), "value")
+	require.NoError(t, os.WriteFile(file, []byte("from-file"), 0o600))
+
+	r := cmd.NewRegistry()
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "plain", EnvKey: "TEST_PLAIN"},
+		&cmd.Parameter{FlagName: "encrypted", EnvKey: "TEST_ENCRYPTED"},
+		&cmd.Parameter{FlagName: "file", EnvKey: "TEST_FILE", AllowFile: true},
+		&cmd.Parameter{FlagName: "file-env", EnvKey: "TEST_FILE_ENV", FileEnvKey: "TEST_FILE_ENV_FILE"},
+		&cmd.Parameter{FlagName: "exec", EnvKey: "TEST_EXEC", AllowExec: true},
+	))
+
+	t.Setenv("TEST_PLAIN", "plain")
+	t.Setenv("TEST_ENCRYPTED", encrypted)
+	t.Setenv("TEST_FILE", "@"+file)
+	t.Setenv("TEST_FILE_ENV_FILE", file)
+	t.Setenv("TEST_EXEC", cmd.ExecValuePrefix+writeSecretProvider(t)+" exec")
+
+	cfg, err := r.Resolve(&cobra.Command{Use: "start"})
+	require.NoError(t, err)
+
+	for name, secret := range map[string]bool{
+		"plain": false, "encrypted": true, "file": true, "file-env": true, "exec": true,
+	} {
+		v, ok := cfg.Get(name)
+		require.True(t, ok)
+		require.Equal(t, secret, v.Secret, name)
+
+		if secret {
+			require.Equal(t, cmd.RedactedValue, v.String(), name)
+		}
+	}
+
+	require.Equal(t, "s3cret", cfg.String("encrypted"))
+}
+
+func TestValidationError(t *testing.T) {
+	errNotFound := errors.New("not found")
+	pathErr := &fs.PathError{Op: "open", Path: "/etc/orb/key.pem", Err: fs.ErrNotExist}
+
+	err := &cmd.ValidationError{Errors: []error{fmt.Errorf("host-url: %w", errNotFound), pathErr}}
+
+	require.True(t, err.Is(errNotFound))
+	require.True(t, err.Is(fs.ErrNotExist))
+	require.False(t, err.Is(errors.New("other")))
+
+	var target *fs.PathError
+	require.True(t, err.As(&target))
+	require.Equal(t, pathErr, target)
+
+	var numErr *strconv.NumError
+	require.False(t, err.As(&numErr))
+
+	require.True(t, errors.Is(err, errNotFound))
+	require.True(t, errors.As(err, &target))
+}
+
+func newTestTLSFields() *cmd.TLSFields {
+	return &cmd.TLSFields{
+		SystemCertPoolFlagName: "tls-systemcertpool",
+		SystemCertPoolEnvKey:   "TEST_TLS_SYSTEMCERTPOOL",
+		CACertsFlagName:        "tls-cacerts",
+		CACertsEnvKey:          "TEST_TLS_CACERTS",
+		CertificateFlagName:    "tls-certificate",
+		CertificateLEnvKey:     "TEST_TLS_CERTIFICATE",
+		KeyFlagName:            "tls-key",
+		KeyEnvKey:              "TEST_TLS_KEY",
+	}
+}
@@ -0,0 +1,282 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Names of the retry parameters. The flag names and environment variables of a RetryGroup are prefixed
+// with the group prefix (e.g. "vct-retry-max-retries" and "ORB_VCT_RETRY_MAX_RETRIES").
+const (
+	RetryMaxRetriesName      = "max-retries"
+	RetryInitialIntervalName = "initial-interval"
+	RetryMaxIntervalName     = "max-interval"
+	RetryMultiplierName      = "multiplier"
+	RetryJitterName          = "jitter"
+	RetryMaxElapsedTimeName  = "max-elapsed-time"
+)
+
+// Default values of the retry parameters.
+const (
+	DefaultRetryMaxRetries      = 3
+	DefaultRetryInitialInterval = 500 * time.Millisecond
+	DefaultRetryMaxInterval     = 30 * time.Second
+	DefaultRetryMultiplier      = 2.0
+	DefaultRetryJitter          = 0.1
+)
+
+// RetryPolicy is an exponential backoff policy resolved by a RetryGroup.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt. Zero disables retries.
+	MaxRetries int
+	// InitialInterval is the interval before the first retry.
+	InitialInterval time.Duration
+	// MaxInterval caps the interval between retries.
+	MaxInterval time.Duration
+	// Multiplier is the factor by which the interval grows after each retry.
+	Multiplier float64
+	// Jitter is the randomization factor (between 0 and 1) applied to each interval. For example, with a jitter
+	// of 0.1 an interval of 1s becomes a random interval between 0.9s and 1.1s.
+	Jitter float64
+	// MaxElapsedTime is the maximum time spent retrying. Zero means no limit.
+	MaxElapsedTime time.Duration
+	// Retryable classifies the errors returned by the retried function. IsRetryableError is used if nil.
+	Retryable func(err error) bool
+}
+
+// Interval returns the interval, without jitter, before the given retry (starting at 1).
+func (p *RetryPolicy) Interval(retry int) time.Duration {
+	if retry < 1 {
+		retry = 1
+	}
+
+	interval := float64(p.InitialInterval) * math.Pow(p.Multiplier, float64(retry-1))
+	if interval > float64(p.MaxInterval) {
+		return p.MaxInterval
+	}
+
+	return time.Duration(interval)
+}
+
+// Do calls fn until it succeeds, it returns an error which isn't retryable, the retries are exhausted, the
+// maximum elapsed time is reached or the context is done. The last error of fn is returned (or the error of
+// the context if it is done while waiting).
+func (p *RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	retryable := p.Retryable
+	if retryable == nil {
+		retryable = IsRetryableError
+	}
+
+	start := time.Now()
+
+	for retry := 1; ; retry++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if !retryable(err) {
+			return err
+		}
+
+		if retry > p.MaxRetries {
+			return fmt.Errorf("giving up after %d attempts: %w", retry, err)
+		}
+
+		interval := p.jitter(p.Interval(retry))
+
+		if p.MaxElapsedTime > 0 && time.Since(start)+interval > p.MaxElapsedTime {
+			return fmt.Errorf("giving up after %d attempts: max elapsed time %s reached: %w", retry,
+				p.MaxElapsedTime, err)
+		}
+
+		timer := time.NewTimer(interval)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (p *RetryPolicy) jitter(interval time.Duration) time.Duration {
+	if p.Jitter == 0 {
+		return interval
+	}
+
+	delta := p.Jitter * float64(interval)
+
+	//nolint:gosec // a weak random number generator is fine for jitter
+	return time.Duration(float64(interval) - delta + rand.Float64()*2*delta)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// PermanentError wraps the given error so that it isn't retried by RetryPolicy.Do. Do returns the error of fn
+// as is, which has the same message as the given error and matches it with errors.Is and errors.As.
+func PermanentError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &permanentError{err: err}
+}
+
+// IsRetryableError is the default classification of RetryPolicy errors. Errors wrapped with PermanentError
+// and context cancellation errors aren't retryable. Errors implementing Retryable() bool or Temporary() bool
+// (e.g. net.Error) are classified by these methods. All other errors are retryable.
+func IsRetryableError(err error) bool {
+	var permanent *permanentError
+	if errors.As(err, &permanent) {
+		return false
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var retryable interface{ Retryable() bool }
+	if errors.As(err, &retryable) {
+		return retryable.Retryable()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		//nolint:staticcheck // Temporary is deprecated but still the best indication available for net errors
+		return netErr.Timeout() || netErr.Temporary()
+	}
+
+	var temporary interface{ Temporary() bool }
+	if errors.As(err, &temporary) {
+		return temporary.Temporary()
+	}
+
+	return true
+}
+
+// RetryGroup is the option group of the parameters of a retry policy.
+type RetryGroup struct {
+	prefix GroupPrefix
+}
+
+var _ OptionGroup[*RetryPolicy] = (*RetryGroup)(nil)
+
+// NewRetryGroup returns a retry option group with the given prefix (e.g. "vct-retry-" and "ORB_VCT_RETRY_").
+func NewRetryGroup(prefix GroupPrefix) *RetryGroup {
+	return &RetryGroup{prefix: prefix}
+}
+
+// Parameters returns the retry parameters.
+func (g *RetryGroup) Parameters() []*Parameter {
+	zero, one := 0.0, 1.0
+
+	return []*Parameter{
+		g.prefix.parameter(RetryMaxRetriesName, &Parameter{
+			Description: "The maximum number of retries. Zero disables retries.",
+			Type:        IntType,
+			Default:     DefaultRetryMaxRetries,
+			Minimum:     &zero,
+		}),
+		g.prefix.parameter(RetryInitialIntervalName, &Parameter{
+			Description:    "The interval before the first retry.",
+			Type:           DurationType,
+			Default:        DefaultRetryInitialInterval,
+			DurationBounds: &DurationBounds{Min: time.Millisecond},
+		}),
+		g.prefix.parameter(RetryMaxIntervalName, &Parameter{
+			Description:    "The maximum interval between retries.",
+			Type:           DurationType,
+			Default:        DefaultRetryMaxInterval,
+			DurationBounds: &DurationBounds{Min: time.Millisecond},
+		}),
+		g.prefix.parameter(RetryMultiplierName, &Parameter{
+			Description: "The factor by which the interval between retries grows after each retry.",
+			Type:        FloatType,
+			Default:     DefaultRetryMultiplier,
+			Minimum:     &one,
+		}),
+		g.prefix.parameter(RetryJitterName, &Parameter{
+			Description: "The randomization factor (between 0 and 1) applied to the interval between retries.",
+			Type:        FloatType,
+			Default:     DefaultRetryJitter,
+			Minimum:     &zero,
+			Maximum:     &one,
+		}),
+		g.prefix.parameter(RetryMaxElapsedTimeName, &Parameter{
+			Description:    "The maximum time spent retrying. Zero means no limit.",
+			Type:           DurationType,
+			DurationBounds: &DurationBounds{},
+		}),
+	}
+}
+
+// AddFlags adds the retry flags to the given command.
+func (g *RetryGroup) AddFlags(cmd *cobra.Command) error {
+	return addGroupFlags(cmd, cmd.Flags(), g.Parameters())
+}
+
+// Resolve resolves the retry policy.
+func (g *RetryGroup) Resolve(cmd *cobra.Command) (*RetryPolicy, error) {
+	cfg, err := resolveGroupConfig(cmd, g.Parameters())
+	if err != nil {
+		return nil, err
+	}
+
+	return &RetryPolicy{
+		MaxRetries:      cfg.Int(g.prefix.FlagName(RetryMaxRetriesName)),
+		InitialInterval: cfg.Duration(g.prefix.FlagName(RetryInitialIntervalName)),
+		MaxInterval:     cfg.Duration(g.prefix.FlagName(RetryMaxIntervalName)),
+		Multiplier:      cfg.Float(g.prefix.FlagName(RetryMultiplierName)),
+		Jitter:          cfg.Float(g.prefix.FlagName(RetryJitterName)),
+		MaxElapsedTime:  cfg.Duration(g.prefix.FlagName(RetryMaxElapsedTimeName)),
+	}, nil
+}
+
+// Validate verifies that the initial interval doesn't exceed the maximum interval and that the maximum
+// elapsed time (if any) isn't shorter than the initial interval.
+func (g *RetryGroup) Validate(policy *RetryPolicy) error {
+	var errs []error
+
+	if policy.InitialInterval > policy.MaxInterval {
+		errs = append(errs, fmt.Errorf("%s [%s] must not be greater than %s [%s]",
+			g.prefix.FlagName(RetryInitialIntervalName), policy.InitialInterval,
+			g.prefix.FlagName(RetryMaxIntervalName), policy.MaxInterval))
+	}
+
+	if policy.MaxElapsedTime > 0 && policy.MaxElapsedTime < policy.InitialInterval {
+		errs = append(errs, fmt.Errorf("%s [%s] must not be less than %s [%s]",
+			g.prefix.FlagName(RetryMaxElapsedTimeName), policy.MaxElapsedTime,
+			g.prefix.FlagName(RetryInitialIntervalName), policy.InitialInterval))
+	}
+
+	if len(errs) > 0 {
+		return &ValidationError{Errors: errs}
+	}
+
+	return nil
+}
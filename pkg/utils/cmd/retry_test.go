@@ -0,0 +1,228 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRetryGroup(t *testing.T) {
+	group := cmd.NewRetryGroup(cmd.GroupPrefix{Flag: "retry-", Env: "TEST_RETRY_"})
+
+	t.Run("defaults", func(t *testing.T) {
+		policy, err := cmd.ResolveGroup[*cmd.RetryPolicy](newCommand(t, group.AddFlags), group)
+		require.NoError(t, err)
+		require.Equal(t, &cmd.RetryPolicy{
+			MaxRetries:      cmd.DefaultRetryMaxRetries,
+			InitialInterval: cmd.DefaultRetryInitialInterval,
+			MaxInterval:     cmd.DefaultRetryMaxInterval,
+			Multiplier:      cmd.DefaultRetryMultiplier,
+			Jitter:          cmd.DefaultRetryJitter,
+		}, policy)
+	})
+
+	t.Run("all parameters", func(t *testing.T) {
+		t.Setenv("TEST_RETRY_MULTIPLIER", "1.5")
+		t.Setenv("TEST_RETRY_MAX_ELAPSED_TIME", "5m")
+
+		policy, err := cmd.ResolveGroup[*cmd.RetryPolicy](newCommand(t, group.AddFlags,
+			"--retry-max-retries", "10", "--retry-initial-interval", "1s", "--retry-max-interval", "1m",
+			"--retry-jitter", "0"), group)
+		require.NoError(t, err)
+		require.Equal(t, &cmd.RetryPolicy{
+			MaxRetries:      10,
+			InitialInterval: time.Second,
+			MaxInterval:     time.Minute,
+			Multiplier:      1.5,
+			MaxElapsedTime:  5 * time.Minute,
+		}, policy)
+	})
+
+	t.Run("out of range", func(t *testing.T) {
+		_, err := group.Resolve(newCommand(t, group.AddFlags, "--retry-max-retries", "-1", "--retry-multiplier", "0.5",
+			"--retry-jitter", "2", "--retry-initial-interval", "0s"))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "retry-max-retries")
+		require.Contains(t, err.Error(), "retry-multiplier")
+		require.Contains(t, err.Error(), "retry-jitter")
+		require.Contains(t, err.Error(), "retry-initial-interval")
+	})
+
+	t.Run("invalid intervals", func(t *testing.T) {
+		_, err := cmd.ResolveGroup[*cmd.RetryPolicy](newCommand(t, group.AddFlags, "--retry-initial-interval", "2m",
+			"--retry-max-interval", "1m", "--retry-max-elapsed-time", "1m"), group)
+		require.EqualError(t, err, "invalid configuration: retry-initial-interval [2m0s] must not be greater "+
+			"than retry-max-interval [1m0s]; retry-max-elapsed-time [1m0s] must not be less than "+
+			"retry-initial-interval [2m0s]")
+	})
+}
+
+func TestRetryPolicy_Interval(t *testing.T) {
+	policy := &cmd.RetryPolicy{InitialInterval: time.Second, MaxInterval: 10 * time.Second, Multiplier: 2}
+
+	require.Equal(t, time.Second, policy.Interval(0))
+	require.Equal(t, time.Second, policy.Interval(1))
+	require.Equal(t, 2*time.Second, policy.Interval(2))
+	require.Equal(t, 8*time.Second, policy.Interval(4))
+	require.Equal(t, 10*time.Second, policy.Interval(5))
+	require.Equal(t, 10*time.Second, policy.Interval(100))
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	policy := &cmd.RetryPolicy{
+		MaxRetries:      3,
+		InitialInterval: time.Millisecond,
+		MaxInterval:     5 * time.Millisecond,
+		Multiplier:      2,
+		Jitter:          0.5,
+	}
+
+	errTransient := errors.New("transient")
+
+	t.Run("success after retries", func(t *testing.T) {
+		attempts := 0
+
+		err := policy.Do(context.Background(), func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errTransient
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, 3, attempts)
+	})
+
+	t.Run("retries exhausted", func(t *testing.T) {
+		attempts := 0
+
+		err := policy.Do(context.Background(), func(context.Context) error {
+			attempts++
+
+			return errTransient
+		})
+		require.ErrorIs(t, err, errTransient)
+		require.EqualError(t, err, "giving up after 4 attempts: transient")
+		require.Equal(t, 4, attempts)
+	})
+
+	t.Run("permanent error", func(t *testing.T) {
+		attempts := 0
+
+		err := policy.Do(context.Background(), func(context.Context) error {
+			attempts++
+
+			return fmt.Errorf("load config: %w", cmd.PermanentError(errTransient))
+		})
+		require.ErrorIs(t, err, errTransient)
+		require.EqualError(t, err, "load config: transient")
+		require.Equal(t, 1, attempts)
+	})
+
+	t.Run("custom classification", func(t *testing.T) {
+		errFatal := errors.New("fatal")
+
+		p := *policy
+		p.Retryable = func(err error) bool { return !errors.Is(err, errFatal) }
+
+		attempts := 0
+
+		err := p.Do(context.Background(), func(context.Context) error {
+			attempts++
+			if attempts == 2 {
+				return errFatal
+			}
+
+			return errTransient
+		})
+		require.Equal(t, errFatal, err)
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("max elapsed time", func(t *testing.T) {
+		p := &cmd.RetryPolicy{
+			MaxRetries:      100,
+			InitialInterval: 40 * time.Millisecond,
+			MaxInterval:     40 * time.Millisecond,
+			Multiplier:      1,
+			MaxElapsedTime:  50 * time.Millisecond,
+		}
+
+		attempts := 0
+
+		err := p.Do(context.Background(), func(context.Context) error {
+			attempts++
+
+			return errTransient
+		})
+		require.ErrorIs(t, err, errTransient)
+		require.Contains(t, err.Error(), "max elapsed time 50ms reached")
+		require.Equal(t, 2, attempts)
+	})
+
+	t.Run("context canceled", func(t *testing.T) {
+		p := &cmd.RetryPolicy{MaxRetries: 3, InitialInterval: time.Hour, MaxInterval: time.Hour, Multiplier: 1}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+
+		err := p.Do(ctx, func(context.Context) error {
+			return errTransient
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("no retries", func(t *testing.T) {
+		p := *policy
+		p.MaxRetries = 0
+
+		attempts := 0
+
+		err := p.Do(context.Background(), func(context.Context) error {
+			attempts++
+
+			return errTransient
+		})
+		require.ErrorIs(t, err, errTransient)
+		require.Equal(t, 1, attempts)
+	})
+}
+
+type retryableError bool
+
+func (e retryableError) Error() string {
+	return "retryable error"
+}
+
+func (e retryableError) Retryable() bool {
+	return bool(e)
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return false }
+
+func TestIsRetryableError(t *testing.T) {
+	require.True(t, cmd.IsRetryableError(errors.New("some error")))
+	require.False(t, cmd.IsRetryableError(cmd.PermanentError(errors.New("some error"))))
+	require.False(t, cmd.IsRetryableError(context.Canceled))
+	require.False(t, cmd.IsRetryableError(context.DeadlineExceeded))
+	require.True(t, cmd.IsRetryableError(retryableError(true)))
+	require.False(t, cmd.IsRetryableError(retryableError(false)))
+	require.True(t, cmd.IsRetryableError(timeoutError{}))
+	require.Nil(t, cmd.PermanentError(nil))
+}
@@ -0,0 +1,135 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// JSONSchemaDraft is the JSON Schema dialect of the schema generated by Registry.JSONSchema.
+	JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+	// durationPattern matches the values accepted by ParseDuration.
+	durationPattern = `^[-+]?(\d+(\.\d*)?|\.\d+)(ns|us|µs|ms|s|m|h|d|w)(\d+(\.\d*)?(ns|us|µs|ms|s|m|h|d|w))*$|^0$|` +
+		`^[-+]?P(\d+([.,]\d+)?W)?(\d+([.,]\d+)?D)?(T(\d+([.,]\d+)?H)?(\d+([.,]\d+)?M)?(\d+([.,]\d+)?S)?)?$`
+)
+
+type schemaItems struct {
+	Type string `json:"type"`
+}
+
+type schemaProperty struct {
+	Type        string       `json:"type"`
+	Description string       `json:"description,omitempty"`
+	Items       *schemaItems `json:"items,omitempty"`
+	Values      *schemaItems `json:"additionalProperties,omitempty"`
+	Default     interface{}  `json:"default,omitempty"`
+	Enum        []string     `json:"enum,omitempty"`
+	Minimum     *float64     `json:"minimum,omitempty"`
+	Maximum     *float64     `json:"maximum,omitempty"`
+	Pattern     string       `json:"pattern,omitempty"`
+	MinDuration string       `json:"x-minimum-duration,omitempty"`
+	MaxDuration string       `json:"x-maximum-duration,omitempty"`
+	Deprecated  bool         `json:"deprecated,omitempty"`
+	EnvKey      string       `json:"x-env-key"`
+}
+
+type schema struct {
+	Schema               string                     `json:"$schema"`
+	Title                string                     `json:"title,omitempty"`
+	Type                 string                     `json:"type"`
+	Properties           map[string]*schemaProperty `json:"properties"`
+	Required             []string                   `json:"required,omitempty"`
+	AdditionalProperties bool                       `json:"additionalProperties"`
+}
+
+// JSONSchema returns a JSON Schema describing the registered parameters. The properties of the schema
+// are keyed by flag name and the corresponding environment variable is given in the "x-env-key" annotation.
+// The schema may be used to validate configuration files and Helm values offline.
+func (r *Registry) JSONSchema(title string) ([]byte, error) {
+	s := &schema{
+		Schema:     JSONSchemaDraft,
+		Title:      title,
+		Type:       "object",
+		Properties: make(map[string]*schemaProperty),
+	}
+
+	for _, p := range r.Parameters() {
+		prop, err := schemaPropertyFor(p)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Properties[p.FlagName] = prop
+
+		if p.Required {
+			s.Required = append(s.Required, p.FlagName)
+		}
+	}
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func schemaPropertyFor(p *Parameter) (*schemaProperty, error) {
+	prop := &schemaProperty{
+		Description: p.Description,
+		Enum:        p.Enum,
+		Minimum:     p.Minimum,
+		Maximum:     p.Maximum,
+		Default:     p.Default,
+		EnvKey:      p.EnvKey,
+	}
+
+	if p.Deprecated != "" {
+		prop.Deprecated = true
+		prop.Description = strings.TrimSpace(fmt.Sprintf("%s Deprecated: %s", prop.Description, p.Deprecated))
+	}
+
+	switch p.Type {
+	case StringType:
+		prop.Type = "string"
+	case StringArrayType, CSVType:
+		prop.Type = "array"
+		prop.Items = &schemaItems{Type: "string"}
+	case StringMapType:
+		prop.Type = "object"
+		prop.Values = &schemaItems{Type: "string"}
+	case BoolType:
+		prop.Type = "boolean"
+	case IntType:
+		prop.Type = "integer"
+	case FloatType:
+		prop.Type = "number"
+	case DurationType:
+		prop.Type = "string"
+		prop.Pattern = durationPattern
+
+		if d, ok := p.Default.(time.Duration); ok {
+			prop.Default = d.String()
+		}
+
+		if b := p.DurationBounds; b != nil {
+			prop.MinDuration = b.Min.String()
+
+			if b.Max > 0 {
+				prop.MaxDuration = b.Max.String()
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported type [%s] for parameter %s", p.Type, p.FlagName)
+	}
+
+	// The default of a secret parameter is omitted since the schema may be published.
+	if p.Secret {
+		prop.Default = nil
+	}
+
+	return prop, nil
+}
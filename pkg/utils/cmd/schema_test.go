@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegistry_JSONSchema(t *testing.T) {
+	minimum := 1.0
+	maximum := 100.0
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{
+			FlagName:    "host-url",
+			EnvKey:      "TEST_HOST_URL",
+			Description: "URL to run the service on.",
+			Required:    true,
+		},
+		&cmd.Parameter{
+			FlagName: "database-type",
+			EnvKey:   "TEST_DATABASE_TYPE",
+			Enum:     []string{"mem", "mongodb"},
+			Default:  "mem",
+		},
+		&cmd.Parameter{
+			FlagName: "cas-url",
+			EnvKey:   "TEST_CAS_URL",
+			Type:     cmd.StringArrayType,
+		},
+		&cmd.Parameter{
+			FlagName: "max-connections",
+			EnvKey:   "TEST_MAX_CONNECTIONS",
+			Type:     cmd.IntType,
+			Minimum:  &minimum,
+			Maximum:  &maximum,
+		},
+		&cmd.Parameter{
+			FlagName: "ratio",
+			EnvKey:   "TEST_RATIO",
+			Type:     cmd.FloatType,
+		},
+		&cmd.Parameter{
+			FlagName: "enable-metrics",
+			EnvKey:   "TEST_ENABLE_METRICS",
+			Type:     cmd.BoolType,
+			Default:  true,
+		},
+		&cmd.Parameter{
+			FlagName:    "timeout",
+			EnvKey:      "TEST_TIMEOUT",
+			Type:        cmd.DurationType,
+			Default:     30 * time.Second,
+			Description: "Request timeout.",
+			DurationBounds: &cmd.DurationBounds{
+				Min: time.Second,
+				Max: time.Minute,
+			},
+			Deprecated: "use request-timeout instead.",
+		},
+		&cmd.Parameter{
+			FlagName: "api-token",
+			EnvKey:   "TEST_API_TOKEN",
+			Default:  "default-token",
+			Secret:   true,
+		},
+	))
+
+	schemaBytes, err := r.JSONSchema("test")
+	require.NoError(t, err)
+
+	s := &struct {
+		Schema     string                            `json:"$schema"`
+		Title      string                            `json:"title"`
+		Type       string                            `json:"type"`
+		Required   []string                          `json:"required"`
+		Properties map[string]map[string]interface{} `json:"properties"`
+	}{}
+
+	require.NoError(t, json.Unmarshal(schemaBytes, s))
+
+	require.Equal(t, cmd.JSONSchemaDraft, s.Schema)
+	require.Equal(t, "test", s.Title)
+	require.Equal(t, "object", s.Type)
+	require.Equal(t, []string{"host-url"}, s.Required)
+	require.Len(t, s.Properties, 8)
+
+	require.Equal(t, "string", s.Properties["host-url"]["type"])
+	require.Equal(t, "TEST_HOST_URL", s.Properties["host-url"]["x-env-key"])
+	require.Equal(t, "URL to run the service on.", s.Properties["host-url"]["description"])
+
+	require.Equal(t, []interface{}{"mem", "mongodb"}, s.Properties["database-type"]["enum"])
+	require.Equal(t, "mem", s.Properties["database-type"]["default"])
+
+	require.Equal(t, "array", s.Properties["cas-url"]["type"])
+	require.Equal(t, map[string]interface{}{"type": "string"}, s.Properties["cas-url"]["items"])
+
+	require.Equal(t, "integer", s.Properties["max-connections"]["type"])
+	require.Equal(t, minimum, s.Properties["max-connections"]["minimum"])
+	require.Equal(t, maximum, s.Properties["max-connections"]["maximum"])
+
+	require.Equal(t, "number", s.Properties["ratio"]["type"])
+
+	require.Equal(t, "boolean", s.Properties["enable-metrics"]["type"])
+	require.Equal(t, true, s.Properties["enable-metrics"]["default"])
+
+	require.Equal(t, "string", s.Properties["timeout"]["type"])
+	require.Equal(t, "30s", s.Properties["timeout"]["default"])
+	require.Equal(t, true, s.Properties["timeout"]["deprecated"])
+	require.Equal(t, "Request timeout. Deprecated: use request-timeout instead.", s.Properties["timeout"]["description"])
+	require.Equal(t, "1s", s.Properties["timeout"]["x-minimum-duration"])
+	require.Equal(t, "1m0s", s.Properties["timeout"]["x-maximum-duration"])
+	require.NotEmpty(t, s.Properties["timeout"]["pattern"])
+
+	require.Equal(t, "string", s.Properties["api-token"]["type"])
+	require.NotContains(t, s.Properties["api-token"], "default")
+	require.NotContains(t, string(schemaBytes), "default-token")
+}
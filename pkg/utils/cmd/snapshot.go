@@ -0,0 +1,353 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+)
+
+const (
+	// FingerprintPrefix is the prefix of the fingerprint of a configuration snapshot.
+	FingerprintPrefix = "sha256:"
+	// SecretHashPrefix is the prefix of the keyed hash which replaces the value of a secret parameter in
+	// a snapshot created with a key.
+	SecretHashPrefix = "hmac-sha256:"
+
+	// snapshotKeyInfo is the HKDF info with which the HMAC key is derived from the snapshot key.
+	snapshotKeyInfo = "cmdutil-go config snapshot hmac v1"
+)
+
+// SnapshotEntry is the value of a parameter in a configuration snapshot.
+type SnapshotEntry struct {
+	FlagName string        `json:"flag"`
+	EnvKey   string        `json:"env"`
+	Type     ParameterType `json:"type"`
+	Source   Source        `json:"source"`
+	Secret   bool          `json:"secret,omitempty"`
+	// Value is the serialized value: durations are formatted as strings and the values of secret parameters
+	// are replaced with a keyed hash (or redacted if the snapshot was created without a key).
+	Value interface{} `json:"value,omitempty"`
+
+	value interface{}
+	// fingerprintValue is the value which contributes to the fingerprint. It's hashed according to the Secret
+	// attribute of the parameter rather than of the value, so that it doesn't depend on the source.
+	fingerprintValue interface{}
+}
+
+// Snapshot is an immutable copy of a resolved configuration. Its serialization is deterministic (the parameters
+// are sorted by flag name) and its fingerprint is a stable hash of the values which may be compared between
+// replicas to detect configuration drift. The values of secret parameters never appear in a snapshot: they are
+// hashed with a keyed HMAC so that a changed secret changes the fingerprint without revealing the secret.
+type Snapshot struct {
+	entries     []*SnapshotEntry
+	index       map[string]*SnapshotEntry
+	fingerprint string
+}
+
+type snapshotOptions struct {
+	key []byte
+}
+
+// SnapshotOption is an option for creating a configuration snapshot.
+type SnapshotOption func(opts *snapshotOptions)
+
+// WithSnapshotKey sets the key from which the key of the HMAC with which the values of secret parameters are
+// hashed is derived (with HKDF-SHA256), so that a key used for another purpose (e.g. the key returned by
+// LoadEncryptionKey) is never used directly as an HMAC key. The key must be the same on all replicas for their
+// fingerprints to match. Without a key, secret parameters only contribute whether they are set to the
+// fingerprint.
+func WithSnapshotKey(key []byte) SnapshotOption {
+	return func(opts *snapshotOptions) {
+		opts.key = key
+	}
+}
+
+// Snapshot returns an immutable snapshot of the resolved parameters.
+func (c *Config) Snapshot(opts ...SnapshotOption) *Snapshot {
+	options := &snapshotOptions{}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var key []byte
+
+	if len(options.key) > 0 {
+		key = deriveKey(options.key, snapshotKeyInfo)
+	}
+
+	s := &Snapshot{
+		entries: make([]*SnapshotEntry, 0, len(c.order)),
+		index:   make(map[string]*SnapshotEntry, len(c.order)),
+	}
+
+	for _, v := range c.Values() {
+		entry := &SnapshotEntry{
+			FlagName: v.Parameter.FlagName,
+			EnvKey:   v.Parameter.EnvKey,
+			Type:     v.Parameter.Type,
+			Source:   v.Source,
+			Secret:   v.Secret,
+			value:    copyValue(v.Value),
+		}
+
+		entry.Value = serializedValue(entry, entry.Secret, key)
+		entry.fingerprintValue = entry.Value
+
+		if v.Parameter.Secret != entry.Secret {
+			entry.fingerprintValue = serializedValue(entry, v.Parameter.Secret, key)
+		}
+
+		s.entries = append(s.entries, entry)
+		s.index[entry.FlagName] = entry
+	}
+
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.entries[i].FlagName < s.entries[j].FlagName
+	})
+
+	s.fingerprint = fingerprint(s.entries)
+
+	return s
+}
+
+// Fingerprint returns the stable hash ("sha256:<hex>") of the parameter values. The sources of the values
+// don't contribute to the fingerprint, so the same value set with a flag, an environment variable or a file
+// results in the same fingerprint: the values of Secret parameters are always hashed with the HMAC (or only
+// contribute whether they are set without a key) and the values of other parameters never are, even if they
+// are redacted in the entries because they were loaded from a file or a secret provider.
+func (s *Snapshot) Fingerprint() string {
+	return s.fingerprint
+}
+
+// Entries returns a copy of the entries of the snapshot sorted by flag name.
+func (s *Snapshot) Entries() []*SnapshotEntry {
+	entries := make([]*SnapshotEntry, len(s.entries))
+
+	for i, e := range s.entries {
+		entry := *e
+		entry.Value = copyValue(e.Value)
+		entry.value = nil
+		entry.fingerprintValue = nil
+
+		entries[i] = &entry
+	}
+
+	return entries
+}
+
+// Source returns the source of the value of the given parameter and false if the parameter isn't in the snapshot.
+func (s *Snapshot) Source(flagName string) (Source, bool) {
+	e, ok := s.index[flagName]
+	if !ok {
+		return "", false
+	}
+
+	return e.Source, true
+}
+
+// String returns the value of the given string parameter or an empty string if the parameter isn't in
+// the snapshot.
+func (s *Snapshot) String(flagName string) string {
+	v, _ := s.value(flagName).(string) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// StringArray returns a copy of the value of the given string array or CSV parameter or nil if the parameter
+// isn't in the snapshot.
+func (s *Snapshot) StringArray(flagName string) []string {
+	v, _ := copyValue(s.value(flagName)).([]string) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// StringMap returns a copy of the value of the given string map parameter or nil if the parameter isn't in
+// the snapshot.
+func (s *Snapshot) StringMap(flagName string) map[string]string {
+	//nolint:errcheck // zero value is returned for other types
+	v, _ := copyValue(s.value(flagName)).(map[string]string)
+
+	return v
+}
+
+// Bool returns the value of the given boolean parameter or false if the parameter isn't in the snapshot.
+func (s *Snapshot) Bool(flagName string) bool {
+	v, _ := s.value(flagName).(bool) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Int returns the value of the given integer parameter or 0 if the parameter isn't in the snapshot.
+func (s *Snapshot) Int(flagName string) int {
+	v, _ := s.value(flagName).(int) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Float returns the value of the given floating point parameter or 0 if the parameter isn't in the snapshot.
+func (s *Snapshot) Float(flagName string) float64 {
+	v, _ := s.value(flagName).(float64) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// Duration returns the value of the given duration parameter or 0 if the parameter isn't in the snapshot.
+func (s *Snapshot) Duration(flagName string) time.Duration {
+	v, _ := s.value(flagName).(time.Duration) //nolint:errcheck // zero value is returned for other types
+
+	return v
+}
+
+// MarshalJSON returns the deterministic JSON serialization of the snapshot: its fingerprint and the entries
+// sorted by flag name.
+func (s *Snapshot) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Fingerprint string           `json:"fingerprint"`
+		Parameters  []*SnapshotEntry `json:"parameters"`
+	}{
+		Fingerprint: s.fingerprint,
+		Parameters:  s.entries,
+	})
+}
+
+// ServeHTTP writes the JSON serialization of the snapshot so that it may be exposed by a version or info
+// endpoint.
+func (s *Snapshot) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	b, err := s.MarshalJSON()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	w.Write(b) //nolint:errcheck,gosec // nothing can be done if the response can't be written
+}
+
+func (s *Snapshot) value(flagName string) interface{} {
+	e, ok := s.index[flagName]
+	if !ok {
+		return nil
+	}
+
+	return e.value
+}
+
+// serializedValue returns the value of the given entry as it appears in the serialization of the snapshot.
+// A secret value is replaced with the HMAC of the canonical JSON encoding of its flag name and value.
+func serializedValue(e *SnapshotEntry, secret bool, key []byte) interface{} {
+	if !secret {
+		return plainValue(e.value)
+	}
+
+	value := plainValue(e.value)
+
+	switch {
+	case value == nil || value == "":
+		return nil
+	case len(key) == 0:
+		return RedactedValue
+	}
+
+	//nolint:errcheck,errchkjson // the values are strings, numbers, booleans, string slices and string maps
+	b, _ := json.Marshal(&struct {
+		FlagName string      `json:"flag"`
+		Value    interface{} `json:"value"`
+	}{
+		FlagName: e.FlagName,
+		Value:    value,
+	})
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(b)
+
+	return SecretHashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// plainValue returns the given value as it appears in the serialization of a snapshot: durations are formatted
+// as strings and empty lists and maps are omitted.
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case time.Duration:
+		return v.String()
+	case []string:
+		if len(v) == 0 {
+			return nil
+		}
+	case map[string]string:
+		if len(v) == 0 {
+			return nil
+		}
+	}
+
+	return copyValue(value)
+}
+
+// deriveKey derives a 32 byte key for the given purpose (info) from the given key with HKDF-SHA256
+// (RFC 5869) without salt.
+func deriveKey(key []byte, info string) []byte {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(key)
+
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte(info))
+	expand.Write([]byte{1})
+
+	return expand.Sum(nil)
+}
+
+func fingerprint(entries []*SnapshotEntry) string {
+	type fingerprintEntry struct {
+		FlagName string      `json:"flag"`
+		Value    interface{} `json:"value"`
+	}
+
+	values := make([]*fingerprintEntry, len(entries))
+
+	for i, e := range entries {
+		values[i] = &fingerprintEntry{FlagName: e.FlagName, Value: e.fingerprintValue}
+	}
+
+	//nolint:errcheck,errchkjson // the values are strings, numbers, booleans, string slices and string maps
+	b, _ := json.Marshal(values)
+
+	sum := sha256.Sum256(b)
+
+	return FingerprintPrefix + hex.EncodeToString(sum[:])
+}
+
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case []string:
+		if v == nil {
+			return v
+		}
+
+		return append([]string{}, v...)
+	case map[string]string:
+		if v == nil {
+			return v
+		}
+
+		m := make(map[string]string, len(v))
+
+		for k, val := range v {
+			m[k] = val
+		}
+
+		return m
+	default:
+		return v
+	}
+}
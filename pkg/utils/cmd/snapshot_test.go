@@ -0,0 +1,257 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestSnapshot(t *testing.T) {
+	r := newSnapshotRegistry(t)
+
+	t.Setenv("TEST_HOST_URL", "localhost:8080")
+	t.Setenv("TEST_DB_PASSWORD", "s3cret")
+	t.Setenv("TEST_LABELS", "b=2,a=1")
+
+	cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--timeout", "1m", "--cas-url", "a", "--cas-url", "b"))
+	require.NoError(t, err)
+
+	key := []byte("0123456789abcdef0123456789abcdef")
+	s := cfg.Snapshot(cmd.WithSnapshotKey(key))
+
+	t.Run("accessors", func(t *testing.T) {
+		require.Equal(t, "localhost:8080", s.String("host-url"))
+		require.Equal(t, "s3cret", s.String("db-password"))
+		require.Equal(t, []string{"a", "b"}, s.StringArray("cas-url"))
+		require.Equal(t, map[string]string{"a": "1", "b": "2"}, s.StringMap("labels"))
+		require.Equal(t, time.Minute, s.Duration("timeout"))
+		require.Equal(t, 5, s.Int("max-conns"))
+		require.True(t, s.Bool("debug"))
+		require.Zero(t, s.Float("host-url"))
+		require.Empty(t, s.String("unknown"))
+
+		source, ok := s.Source("timeout")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceFlag, source)
+
+		_, ok = s.Source("unknown")
+		require.False(t, ok)
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		s.StringArray("cas-url")[0] = "changed"
+		s.StringMap("labels")["a"] = "changed"
+		cfg.StringArray("cas-url")[1] = "changed"
+
+		require.Equal(t, []string{"a", "b"}, s.StringArray("cas-url"))
+		require.Equal(t, "1", s.StringMap("labels")["a"])
+
+		entries := s.Entries()
+		entries[0].FlagName = "changed"
+		require.Equal(t, "cas-url", s.Entries()[0].FlagName)
+	})
+
+	t.Run("serialization", func(t *testing.T) {
+		b, err := json.Marshal(s)
+		require.NoError(t, err)
+		require.NotContains(t, string(b), "s3cret")
+
+		var doc struct {
+			Fingerprint string                   `json:"fingerprint"`
+			Parameters  []map[string]interface{} `json:"parameters"`
+		}
+
+		require.NoError(t, json.Unmarshal(b, &doc))
+		require.Equal(t, s.Fingerprint(), doc.Fingerprint)
+		require.Len(t, doc.Parameters, 7)
+
+		names := make([]string, len(doc.Parameters))
+		for i, p := range doc.Parameters {
+			names[i] = p["flag"].(string)
+		}
+
+		require.Equal(t, []string{"cas-url", "db-password", "debug", "host-url", "labels", "max-conns", "timeout"},
+			names)
+
+		require.Equal(t, true, doc.Parameters[1]["secret"])
+		require.Equal(t, secretHash(key, `{"flag":"db-password","value":"s3cret"}`), doc.Parameters[1]["value"])
+		require.Equal(t, "1m0s", doc.Parameters[6]["value"])
+		require.Equal(t, "flag", doc.Parameters[6]["source"])
+
+		resolved, err := r.Resolve(newCommand(t, registryFlags(r), "--timeout", "1m", "--cas-url", "a", "--cas-url", "b"))
+		require.NoError(t, err)
+
+		again, err := json.Marshal(resolved.Snapshot(cmd.WithSnapshotKey(key)))
+		require.NoError(t, err)
+		require.Equal(t, string(b), string(again))
+	})
+
+	t.Run("file source", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "password")
+		require.NoError(t, os.WriteFile(file, []byte("s3cret\n"), 0o600))
+
+		t.Setenv("TEST_DB_PASSWORD", "")
+		t.Setenv("TEST_DB_PASSWORD_FILE", file)
+
+		resolved, err := r.Resolve(newCommand(t, registryFlags(r), "--timeout", "1m", "--cas-url", "a", "--cas-url", "b"))
+		require.NoError(t, err)
+
+		fromFile := resolved.Snapshot(cmd.WithSnapshotKey(key))
+
+		source, ok := fromFile.Source("db-password")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceFile, source)
+		require.Equal(t, s.Fingerprint(), fromFile.Fingerprint())
+	})
+
+	t.Run("info endpoint", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+
+		s.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/info", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		require.Contains(t, rec.Body.String(), `"fingerprint":"`+s.Fingerprint()+`"`)
+	})
+}
+
+func TestSnapshotFingerprint(t *testing.T) {
+	r := newSnapshotRegistry(t)
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	fingerprint := func(t *testing.T, opts []cmd.SnapshotOption, args ...string) string {
+		t.Helper()
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r), args...))
+		require.NoError(t, err)
+
+		return cfg.Snapshot(opts...).Fingerprint()
+	}
+
+	t.Setenv("TEST_DB_PASSWORD", "s3cret")
+
+	base := fingerprint(t, []cmd.SnapshotOption{cmd.WithSnapshotKey(key)}, "--host-url", "localhost:8080")
+	require.True(t, strings.HasPrefix(base, cmd.FingerprintPrefix))
+
+	t.Run("stable", func(t *testing.T) {
+		require.Equal(t, base, fingerprint(t, []cmd.SnapshotOption{cmd.WithSnapshotKey(key)},
+			"--host-url", "localhost:8080"))
+	})
+
+	t.Run("source doesn't matter", func(t *testing.T) {
+		t.Setenv("TEST_HOST_URL", "localhost:8080")
+
+		require.Equal(t, base, fingerprint(t, []cmd.SnapshotOption{cmd.WithSnapshotKey(key)}))
+	})
+
+	t.Run("value loaded from a file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "host-url")
+		require.NoError(t, os.WriteFile(file, []byte("localhost:8080"), 0o600))
+
+		for _, opts := range [][]cmd.SnapshotOption{{cmd.WithSnapshotKey(key)}, nil} {
+			cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--host-url", "@"+file))
+			require.NoError(t, err)
+
+			s := cfg.Snapshot(opts...)
+			require.Equal(t, fingerprint(t, opts, "--host-url", "localhost:8080"), s.Fingerprint())
+
+			for _, e := range s.Entries() {
+				if e.FlagName == "host-url" {
+					require.True(t, e.Secret)
+					require.NotEqual(t, "localhost:8080", e.Value)
+				}
+			}
+		}
+	})
+
+	t.Run("non-secret value changed", func(t *testing.T) {
+		require.NotEqual(t, base, fingerprint(t, []cmd.SnapshotOption{cmd.WithSnapshotKey(key)},
+			"--host-url", "localhost:9090"))
+	})
+
+	t.Run("secret changed", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "other")
+
+		require.NotEqual(t, base, fingerprint(t, []cmd.SnapshotOption{cmd.WithSnapshotKey(key)},
+			"--host-url", "localhost:8080"))
+	})
+
+	t.Run("different key", func(t *testing.T) {
+		require.NotEqual(t, base, fingerprint(t, []cmd.SnapshotOption{cmd.WithSnapshotKey([]byte("other"))},
+			"--host-url", "localhost:8080"))
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		withoutKey := fingerprint(t, nil, "--host-url", "localhost:8080")
+		require.NotEqual(t, base, withoutKey)
+
+		t.Setenv("TEST_DB_PASSWORD", "other")
+		require.Equal(t, withoutKey, fingerprint(t, nil, "--host-url", "localhost:8080"))
+
+		t.Setenv("TEST_DB_PASSWORD", "")
+		require.NotEqual(t, withoutKey, fingerprint(t, nil, "--host-url", "localhost:8080"))
+	})
+
+	t.Run("redacted without key", func(t *testing.T) {
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.NoError(t, err)
+
+		for _, e := range cfg.Snapshot().Entries() {
+			if e.Secret {
+				require.Equal(t, cmd.RedactedValue, e.Value)
+			}
+		}
+	})
+}
+
+func newSnapshotRegistry(t *testing.T) *cmd.Registry {
+	t.Helper()
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "host-url", EnvKey: "TEST_HOST_URL", AllowFile: true},
+		&cmd.Parameter{
+			FlagName: "db-password", EnvKey: "TEST_DB_PASSWORD", FileEnvKey: "TEST_DB_PASSWORD_FILE", Secret: true,
+		},
+		&cmd.Parameter{FlagName: "timeout", EnvKey: "TEST_TIMEOUT", Type: cmd.DurationType},
+		&cmd.Parameter{FlagName: "cas-url", EnvKey: "TEST_CAS_URL", Type: cmd.StringArrayType},
+		&cmd.Parameter{FlagName: "labels", EnvKey: "TEST_LABELS", Type: cmd.StringMapType},
+		&cmd.Parameter{FlagName: "max-conns", EnvKey: "TEST_MAX_CONNS", Type: cmd.IntType, Default: 5},
+		&cmd.Parameter{FlagName: "debug", EnvKey: "TEST_DEBUG", Type: cmd.BoolType, Default: true},
+	))
+
+	return r
+}
+
+// secretHash returns the expected hash of the given canonical JSON encoding of a secret entry: an HMAC with
+// the key derived from the given key with HKDF-SHA256 (RFC 5869).
+func secretHash(key []byte, canonical string) string {
+	extract := hmac.New(sha256.New, make([]byte, sha256.Size))
+	extract.Write(key)
+
+	expand := hmac.New(sha256.New, extract.Sum(nil))
+	expand.Write([]byte("cmdutil-go config snapshot hmac v1\x01"))
+
+	mac := hmac.New(sha256.New, expand.Sum(nil))
+	mac.Write([]byte(canonical))
+
+	return cmd.SecretHashPrefix + hex.EncodeToString(mac.Sum(nil))
+}
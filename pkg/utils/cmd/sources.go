@@ -0,0 +1,177 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	"github.com/trustbloc/cmdutil-go/internal/logfields"
+)
+
+// SourcePolicy defines how Registry.Resolve handles a parameter which is set via a source which isn't
+// in its AllowedSources.
+type SourcePolicy int
+
+const (
+	// SourcePolicyFail fails the resolution with an error for each violation. This is the default policy.
+	SourcePolicyFail SourcePolicy = iota
+	// SourcePolicyWarn logs a warning for each violation and resolves the value anyway. The violations are
+	// available with Config.SourceViolations.
+	SourcePolicyWarn
+)
+
+// WithSourcePolicy sets the policy applied to parameters set via a source which isn't allowed.
+func WithSourcePolicy(policy SourcePolicy) ResolveOption {
+	return func(opts *resolveOptions) {
+		opts.sourcePolicy = policy
+	}
+}
+
+// SourceViolation describes a parameter set via a source which isn't in its AllowedSources.
+type SourceViolation struct {
+	// Parameter is the parameter.
+	Parameter *Parameter
+	// Key is the command line flag (e.g. "--db-password") or environment variable which set the value.
+	Key string
+	// Source is the disallowed source of the value.
+	Source Source
+}
+
+// Error returns a description of the violation.
+func (v *SourceViolation) Error() string {
+	return fmt.Sprintf("%s (%s): value set via %s with %s is not allowed, allowed sources: %s",
+		v.Parameter.FlagName, v.Parameter.EnvKey, v.Source, v.Key, strings.Join(allowedSources(v.Parameter), ", "))
+}
+
+// CheckSources returns the parameters which are set via a source which isn't in their AllowedSources,
+// without resolving them, e.g. to report violations at startup.
+func (r *Registry) CheckSources(cmd *cobra.Command) []*SourceViolation {
+	var violations []*SourceViolation
+
+	for _, p := range r.Parameters() {
+		if v := checkSource(cmd, p); v != nil {
+			violations = append(violations, v)
+		}
+	}
+
+	return violations
+}
+
+func logSourceViolation(v *SourceViolation) {
+	logger.Warn("Configuration parameter set via a disallowed source",
+		logfields.WithParameter(v.Parameter.FlagName),
+		logfields.WithConfigKey(v.Key),
+		logfields.WithSource(string(v.Source)),
+		logfields.WithAllowedSources(allowedSources(v.Parameter)),
+	)
+}
+
+// checkSource returns a violation if the given parameter is set via a source which isn't allowed.
+func checkSource(cmd *cobra.Command, p *Parameter) *SourceViolation {
+	if len(p.AllowedSources) == 0 {
+		return nil
+	}
+
+	source, key := sourceOf(cmd, p)
+	if source == SourceDefault {
+		return nil
+	}
+
+	for _, s := range p.AllowedSources {
+		if s == source {
+			return nil
+		}
+	}
+
+	return &SourceViolation{Parameter: p, Key: key, Source: source}
+}
+
+// sourceOf returns the source of the value of the given parameter along with the command line flag or
+// environment variable which set it. SourceDefault (and an empty key) is returned if the parameter isn't set.
+func sourceOf(cmd *cobra.Command, p *Parameter) (Source, string) {
+	if f := cmd.Flags().Lookup(p.FlagName); f != nil && f.Changed {
+		return rawValueSource(p, flagValues(f), SourceFlag), "--" + p.FlagName
+	}
+
+	if v := os.Getenv(p.EnvKey); v != "" {
+		return rawValueSource(p, []string{v}, SourceEnv), p.EnvKey
+	}
+
+	if p.FileEnvKey != "" && os.Getenv(p.FileEnvKey) != "" {
+		return SourceFile, p.FileEnvKey
+	}
+
+	if p.Exec != "" {
+		return SourceExec, p.FlagName
+	}
+
+	return SourceDefault, ""
+}
+
+// rawValueSource returns the source of the given raw values: a literal value is attributed to the flag or
+// environment variable itself, otherwise the values are obtained from a secret provider or loaded from files
+// (if the parameter allows it).
+func rawValueSource(p *Parameter, values []string, literal Source) Source {
+	source := SourceFile
+
+	for _, v := range values {
+		if p.AllowExec && IsExecValue(v) {
+			source = SourceExec
+		} else if _, ok := valueFile(v); !ok || !p.AllowFile {
+			return literal
+		}
+	}
+
+	return source
+}
+
+// isSecretValue returns true if the value of the given parameter set via the given source is sensitive: the
+// parameter is Secret or the value is decrypted, obtained from a secret provider or loaded from a file.
+func isSecretValue(cmd *cobra.Command, p *Parameter, source Source) bool {
+	if p.Secret || source == SourceFile || source == SourceExec {
+		return true
+	}
+
+	var values []string
+
+	if f := cmd.Flags().Lookup(p.FlagName); f != nil && f.Changed {
+		values = flagValues(f)
+	} else if v := os.Getenv(p.EnvKey); v != "" {
+		values = []string{v}
+	}
+
+	for _, v := range values {
+		if _, ok := valueFile(v); strings.Contains(v, EncryptedValuePrefix) || (p.AllowFile && ok) ||
+			(p.AllowExec && strings.Contains(v, ExecValuePrefix)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func flagValues(f *pflag.Flag) []string {
+	if s, ok := f.Value.(pflag.SliceValue); ok {
+		return s.GetSlice()
+	}
+
+	return []string{f.Value.String()}
+}
+
+func allowedSources(p *Parameter) []string {
+	sources := make([]string, len(p.AllowedSources))
+
+	for i, s := range p.AllowedSources {
+		sources[i] = string(s)
+	}
+
+	return sources
+}
@@ -0,0 +1,152 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestAllowedSources(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cret\n"), 0o600))
+
+	r := newSourcesRegistry(t)
+
+	t.Run("file environment variable", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD_FILE", passwordFile)
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.NoError(t, err)
+		require.Equal(t, "s3cret", cfg.String("db-password"))
+		require.Empty(t, cfg.SourceViolations())
+
+		v, ok := cfg.Get("db-password")
+		require.True(t, ok)
+		require.Equal(t, cmd.SourceFile, v.Source)
+	})
+
+	t.Run("file referenced by flag", func(t *testing.T) {
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--db-password", "@"+passwordFile))
+		require.NoError(t, err)
+		require.Equal(t, "s3cret", cfg.String("db-password"))
+	})
+
+	t.Run("secret provider", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", cmd.ExecValuePrefix+writeSecretProvider(t)+" db")
+
+		cfg, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.NoError(t, err)
+		require.Equal(t, "secret-db", cfg.String("db-password"))
+	})
+
+	t.Run("flag rejected", func(t *testing.T) {
+		_, err := r.Resolve(newCommand(t, registryFlags(r), "--db-password", "s3cret"))
+		require.EqualError(t, err, "invalid configuration: db-password (TEST_DB_PASSWORD): value set via flag "+
+			"with --db-password is not allowed, allowed sources: file, exec")
+
+		var violation *cmd.SourceViolation
+		require.True(t, errors.As(err, &violation))
+		require.Equal(t, cmd.SourceFlag, violation.Source)
+		require.NotContains(t, err.Error(), "s3cret")
+	})
+
+	t.Run("environment variable rejected", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "s3cret")
+
+		_, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.EqualError(t, err, "invalid configuration: db-password (TEST_DB_PASSWORD): value set via env "+
+			"with TEST_DB_PASSWORD is not allowed, allowed sources: file, exec")
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "s3cret")
+
+		command := newCommand(t, registryFlags(r), "--api-key", "@"+passwordFile)
+
+		cfg, err := r.Resolve(command, cmd.WithSourcePolicy(cmd.SourcePolicyWarn))
+		require.NoError(t, err)
+		require.Equal(t, "s3cret", cfg.String("db-password"))
+		require.Equal(t, "s3cret", cfg.String("api-key"))
+
+		violations := cfg.SourceViolations()
+		require.Len(t, violations, 2)
+		require.Equal(t, "db-password", violations[0].Parameter.FlagName)
+		require.Equal(t, "TEST_DB_PASSWORD", violations[0].Key)
+		require.Equal(t, cmd.SourceEnv, violations[0].Source)
+		require.Equal(t, "api-key", violations[1].Parameter.FlagName)
+		require.Equal(t, "--api-key", violations[1].Key)
+		require.Equal(t, cmd.SourceFile, violations[1].Source)
+
+		require.Equal(t, violations, r.CheckSources(command))
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		_, err := r.Resolve(newCommand(t, registryFlags(r)))
+		require.EqualError(t, err, "invalid configuration: Neither db-password (command line flag) nor "+
+			"TEST_DB_PASSWORD or TEST_DB_PASSWORD_FILE (environment variables) have been set.")
+	})
+
+	t.Run("unknown keys", func(t *testing.T) {
+		require.Empty(t, r.FindUnknownEnvKeys("TEST_", []string{"TEST_DB_PASSWORD_FILE=/run/secrets/db"}))
+	})
+}
+
+func TestAllowedSourcesRegistration(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	err := r.Register(&cmd.Parameter{
+		FlagName: "timeout", EnvKey: "TEST_TIMEOUT", Type: cmd.DurationType, FileEnvKey: "TEST_TIMEOUT_FILE",
+	})
+	require.EqualError(t, err, "a file environment variable is only supported for string parameters: timeout")
+
+	err = r.Register(&cmd.Parameter{FlagName: "token", EnvKey: "TEST_TOKEN", FileEnvKey: "TEST_TOKEN"})
+	require.EqualError(t, err, "the file environment variable of parameter token must differ from TEST_TOKEN")
+
+	err = r.Register(&cmd.Parameter{
+		FlagName: "token", EnvKey: "TEST_TOKEN", AllowedSources: []cmd.Source{"vault"},
+	})
+	require.EqualError(t, err, "unsupported allowed source [vault] for parameter token")
+
+	require.NoError(t, r.Register(&cmd.Parameter{FlagName: "token", EnvKey: "TEST_TOKEN"}))
+
+	err = r.Register(&cmd.Parameter{FlagName: "other", EnvKey: "TEST_OTHER", FileEnvKey: "TEST_TOKEN"})
+	require.EqualError(t, err, "environment variable TEST_TOKEN is already registered")
+}
+
+func newSourcesRegistry(t *testing.T) *cmd.Registry {
+	t.Helper()
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{
+			FlagName:       "db-password",
+			EnvKey:         "TEST_DB_PASSWORD",
+			FileEnvKey:     "TEST_DB_PASSWORD_FILE",
+			Required:       true,
+			Secret:         true,
+			AllowFile:      true,
+			AllowExec:      true,
+			AllowedSources: []cmd.Source{cmd.SourceFile, cmd.SourceExec},
+		},
+		&cmd.Parameter{
+			FlagName:       "api-key",
+			EnvKey:         "TEST_API_KEY",
+			Secret:         true,
+			AllowFile:      true,
+			AllowedSources: []cmd.Source{cmd.SourceEnv},
+		},
+	))
+
+	return r
+}
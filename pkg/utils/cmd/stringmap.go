@@ -0,0 +1,222 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	mapEntrySeparator = ','
+	mapKeySeparator   = '='
+	mapEscape         = '\\'
+)
+
+// DuplicateKeyPolicy defines how GetStringMap handles a key which is set more than once.
+type DuplicateKeyPolicy string
+
+// Duplicate key policies.
+const (
+	// DuplicateKeyError fails if a key is set more than once. This is the default policy.
+	DuplicateKeyError DuplicateKeyPolicy = ""
+	// DuplicateKeyFirst keeps the first value of a key which is set more than once.
+	DuplicateKeyFirst DuplicateKeyPolicy = "first"
+	// DuplicateKeyLast keeps the last value of a key which is set more than once.
+	DuplicateKeyLast DuplicateKeyPolicy = "last"
+)
+
+// GetOptionalStringMap returns the map set via either command line flag or environment variable.
+// See GetStringMap for details. If the variable isn't set, then an empty map will be returned.
+func GetOptionalStringMap(cmd *cobra.Command, flagName, envKey string, policy DuplicateKeyPolicy) map[string]string {
+	//nolint // the error is ignored for an optional var
+	v, _ := GetStringMap(cmd, flagName, envKey, policy, true)
+
+	return v
+}
+
+// GetStringMap returns the map set via either command line flag or environment variable.
+// If both are set, then the command line flag takes precedence.
+// For the command line flag, the entries must be set using repeated flags (e.g. --label k1=v1 --label k2=v2).
+// For the environment variable, the entries are comma-separated (e.g. k1=v1,k2=v2).
+// The command line flag must be set as a StringArray.
+// The characters "=", "," and "\" may be escaped with a backslash (e.g. k=a\,b sets k to "a,b").
+// A key which is set more than once is handled according to the given policy.
+// If the variable isn't set, then an error will be returned.
+func GetStringMap(cmd *cobra.Command, flagName, envKey string, policy DuplicateKeyPolicy,
+	isOptional bool) (map[string]string, error) {
+	if cmd.Flags().Changed(flagName) {
+		value, err := cmd.Flags().GetStringArray(flagName)
+		if err != nil {
+			return nil, fmt.Errorf(flagName+" flag not found: %s", err)
+		}
+
+		if len(value) == 0 {
+			return nil, fmt.Errorf("%s value is empty", flagName)
+		}
+
+		return parseStringMap(cmd, flagName, value, policy, resolveValue)
+	}
+
+	value, isSet := os.LookupEnv(envKey)
+
+	if isOptional || isSet {
+		if !isOptional && value == "" {
+			return nil, fmt.Errorf("%s value is empty", envKey)
+		}
+
+		if value == "" {
+			return map[string]string{}, nil
+		}
+
+		return parseStringMap(cmd, envKey, splitEscaped(value, mapEntrySeparator), policy, resolveValue)
+	}
+
+	return nil, errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
+		" (environment variable) have been set.")
+}
+
+// ParseStringMap parses comma-separated key=value entries (e.g. k1=v1,k2=v2) as accepted by GetStringMap
+// in an environment variable. The values are taken literally: unlike with GetStringMap, they aren't loaded
+// from files, obtained from secret providers or decrypted.
+func ParseStringMap(s string, policy DuplicateKeyPolicy) (map[string]string, error) {
+	if s == "" {
+		return map[string]string{}, nil
+	}
+
+	return parseStringMap(nil, "value", splitEscaped(s, mapEntrySeparator), policy, nil)
+}
+
+// FormatStringMap returns the comma-separated key=value representation of the given map, sorted by key and
+// escaped so that it can be parsed by ParseStringMap.
+func FormatStringMap(m map[string]string) string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	entries := make([]string, len(keys))
+
+	for i, k := range keys {
+		entries[i] = escapeMapToken(k) + string(mapKeySeparator) + escapeMapToken(m[k])
+	}
+
+	return strings.Join(entries, string(mapEntrySeparator))
+}
+
+// parseStringMap parses the given key=value entries. The values are resolved with the given resolver unless
+// it is nil.
+func parseStringMap(cmd *cobra.Command, name string, entries []string, policy DuplicateKeyPolicy,
+	resolve valueResolver) (map[string]string, error) {
+	m := make(map[string]string, len(entries))
+
+	for _, entry := range entries {
+		parts := splitEscaped(entry, mapKeySeparator)
+		if len(parts) < 2 { //nolint:gomnd // key and value
+			return nil, fmt.Errorf("invalid entry [%s] for %s: expecting key=value", entry, name)
+		}
+
+		key := unescapeMapToken(strings.TrimSpace(parts[0]))
+		if key == "" {
+			return nil, fmt.Errorf("invalid entry [%s] for %s: key is empty", entry, name)
+		}
+
+		// Only the first unescaped "=" separates the key from the value.
+		value, err := resolveWith(resolve, cmd, name, unescapeMapToken(strings.Join(parts[1:], string(mapKeySeparator))))
+		if err != nil {
+			return nil, err
+		}
+
+		if _, exists := m[key]; exists {
+			switch policy {
+			case DuplicateKeyFirst:
+				continue
+			case DuplicateKeyLast:
+			default:
+				return nil, fmt.Errorf("duplicate key [%s] for %s", key, name)
+			}
+		}
+
+		m[key] = value
+	}
+
+	return m, nil
+}
+
+// splitEscaped splits s around each instance of sep which isn't escaped with a backslash. The escape
+// sequences are retained in the returned tokens.
+func splitEscaped(s string, sep rune) []string {
+	var (
+		tokens  []string
+		current strings.Builder
+		escaped bool
+	)
+
+	for _, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(mapEscape)
+			current.WriteRune(r)
+
+			escaped = false
+		case r == mapEscape:
+			escaped = true
+		case r == sep:
+			tokens = append(tokens, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if escaped {
+		current.WriteRune(mapEscape)
+	}
+
+	return append(tokens, current.String())
+}
+
+func unescapeMapToken(s string) string {
+	var (
+		b       strings.Builder
+		escaped bool
+	)
+
+	for _, r := range s {
+		if r == mapEscape && !escaped {
+			escaped = true
+
+			continue
+		}
+
+		if escaped && r != mapEntrySeparator && r != mapKeySeparator && r != mapEscape {
+			// Unknown escape sequences are kept as is.
+			b.WriteRune(mapEscape)
+		}
+
+		b.WriteRune(r)
+
+		escaped = false
+	}
+
+	if escaped {
+		b.WriteRune(mapEscape)
+	}
+
+	return b.String()
+}
+
+func escapeMapToken(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ",", `\,`, "=", `\=`).Replace(s)
+}
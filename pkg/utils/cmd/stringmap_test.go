@@ -0,0 +1,187 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const (
+	labelFlagName = "label"
+	labelEnvKey   = "TEST_LABEL"
+)
+
+func TestGetStringMap(t *testing.T) {
+	t.Run("flag", func(t *testing.T) {
+		command := newCommand(t, labelFlags, "--label", "app=orb", "--label", "url=https://host/path?a=b",
+			"--label", `k\=1=v\,2`)
+
+		m, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"app": "orb", "url": "https://host/path?a=b", "k=1": "v,2"}, m)
+	})
+
+	t.Run("flag takes precedence", func(t *testing.T) {
+		t.Setenv(labelEnvKey, "app=env")
+
+		command := newCommand(t, labelFlags, "--label", "app=flag")
+
+		m, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"app": "flag"}, m)
+	})
+
+	t.Run("env var", func(t *testing.T) {
+		t.Setenv(labelEnvKey, `app=orb, tier = backend ,list=a\,b,eq=x\=y,path=c:\\dir,empty=`)
+
+		m, err := cmd.GetStringMap(newCommand(t, labelFlags), labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{
+			"app": "orb", "tier": " backend ", "list": "a,b", "eq": "x=y", "path": `c:\dir`, "empty": "",
+		}, m)
+	})
+
+	t.Run("duplicate keys", func(t *testing.T) {
+		t.Setenv(labelEnvKey, "app=first,app=last")
+
+		command := newCommand(t, labelFlags)
+
+		_, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.EqualError(t, err, "duplicate key [app] for TEST_LABEL")
+
+		m, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyFirst, false)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"app": "first"}, m)
+
+		m, err = cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyLast, false)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"app": "last"}, m)
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		command := newCommand(t, labelFlags, "--label", "app")
+
+		_, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.EqualError(t, err, "invalid entry [app] for label: expecting key=value")
+
+		t.Setenv(labelEnvKey, `app=orb,=value`)
+
+		_, err = cmd.GetStringMap(newCommand(t, labelFlags), labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.EqualError(t, err, "invalid entry [=value] for TEST_LABEL: key is empty")
+
+		t.Setenv(labelEnvKey, `app\=orb`)
+
+		_, err = cmd.GetStringMap(newCommand(t, labelFlags), labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.EqualError(t, err, `invalid entry [app\=orb] for TEST_LABEL: expecting key=value`)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		command := newCommand(t, labelFlags)
+
+		_, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.EqualError(t, err,
+			"Neither label (command line flag) nor TEST_LABEL (environment variable) have been set.")
+
+		m, err := cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, true)
+		require.NoError(t, err)
+		require.Empty(t, m)
+		require.NotNil(t, m)
+	})
+
+	t.Run("empty values", func(t *testing.T) {
+		t.Setenv(labelEnvKey, "")
+
+		_, err := cmd.GetStringMap(newCommand(t, labelFlags), labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.EqualError(t, err, "TEST_LABEL value is empty")
+
+		command := &cobra.Command{Use: "start", Run: func(*cobra.Command, []string) {}}
+		command.Flags().String(labelFlagName, "", "")
+		command.SetArgs([]string{"--label", "app=orb"})
+		require.NoError(t, command.Execute())
+
+		_, err = cmd.GetStringMap(command, labelFlagName, labelEnvKey, cmd.DuplicateKeyError, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "label flag not found")
+	})
+}
+
+func TestGetOptionalStringMap(t *testing.T) {
+	t.Setenv(labelEnvKey, "app=orb")
+
+	require.Equal(t, map[string]string{"app": "orb"},
+		cmd.GetOptionalStringMap(newCommand(t, labelFlags), labelFlagName, labelEnvKey, cmd.DuplicateKeyError))
+
+	t.Setenv(labelEnvKey, "app")
+
+	require.Nil(t, cmd.GetOptionalStringMap(newCommand(t, labelFlags), labelFlagName, labelEnvKey, cmd.DuplicateKeyError))
+}
+
+func TestFormatStringMap(t *testing.T) {
+	m := map[string]string{"b": "x,y", "a": "1", `k=\`: "v=w"}
+
+	s := cmd.FormatStringMap(m)
+	require.Equal(t, `a=1,b=x\,y,k\=\\=v\=w`, s)
+
+	parsed, err := cmd.ParseStringMap(s, cmd.DuplicateKeyError)
+	require.NoError(t, err)
+	require.Equal(t, m, parsed)
+
+	parsed, err = cmd.ParseStringMap("", cmd.DuplicateKeyError)
+	require.NoError(t, err)
+	require.Empty(t, parsed)
+
+	parsed, err = cmd.ParseStringMap(`a=\n\`, cmd.DuplicateKeyError)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"a": `\n\`}, parsed)
+
+	_, err = cmd.ParseStringMap("a", cmd.DuplicateKeyError)
+	require.EqualError(t, err, "invalid entry [a] for value: expecting key=value")
+}
+
+func TestResolve_StringMap(t *testing.T) {
+	r := cmd.NewRegistry()
+	require.NoError(t, r.Register(
+		&cmd.Parameter{
+			FlagName: labelFlagName, EnvKey: labelEnvKey, Type: cmd.StringMapType,
+			Default: map[string]string{"app": "default"},
+		},
+		&cmd.Parameter{
+			FlagName: "header", EnvKey: "TEST_HEADER", Type: cmd.StringMapType, DuplicateKeys: cmd.DuplicateKeyLast,
+		},
+	))
+
+	cfg, err := r.Resolve(newCommand(t, registryFlags(r), "--header", "Accept=a", "--header", "Accept=b"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"app": "default"}, cfg.StringMap(labelFlagName))
+	require.Equal(t, map[string]string{"Accept": "b"}, cfg.StringMap("header"))
+	require.Nil(t, cfg.StringMap("unknown"))
+
+	v, ok := cfg.Get(labelFlagName)
+	require.True(t, ok)
+	require.Equal(t, "app=default", v.String())
+
+	err = r.Register(&cmd.Parameter{FlagName: "x", EnvKey: "X", Type: cmd.StringMapType, DuplicateKeys: "merge"})
+	require.EqualError(t, err, "unsupported duplicate key policy [merge] for parameter x")
+
+	schema, err := r.JSONSchema("")
+	require.NoError(t, err)
+	require.Contains(t, string(schema), `"type": "object",
+      "additionalProperties": {
+        "type": "string"
+      },`)
+}
+
+// labelFlags adds the label flag.
+func labelFlags(command *cobra.Command) error {
+	command.Flags().StringArray(labelFlagName, nil, "")
+
+	return nil
+}
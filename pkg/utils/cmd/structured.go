@@ -0,0 +1,89 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// GetStructured decodes the JSON or YAML value set via either command line flag or environment variable into
+// the value pointed to by v. If both are set, then the command line flag takes precedence. The value may be
+// loaded from a file with "@path" (or from standard input with "@-").
+// The value is decoded using the JSON field tags of v and unknown fields are rejected.
+// If the variable isn't set and isOptional is true, then v is left unchanged.
+func GetStructured(cmd *cobra.Command, flagName, envKey string, v interface{}, isOptional bool) error {
+	value, err := getString(cmd, flagName, envKey, isOptional, resolveFileValue)
+	if err != nil {
+		return err
+	}
+
+	if value == "" {
+		return nil
+	}
+
+	if err := DecodeStructured([]byte(value), v); err != nil {
+		name, raw := rawValue(cmd, flagName, envKey)
+
+		if path, ok := valueFile(raw); ok {
+			if raw == StdinValue {
+				return fmt.Errorf("invalid value for %s (standard input): %w", name, err)
+			}
+
+			return fmt.Errorf("invalid value for %s (file %s): %w", name, path, err)
+		}
+
+		return fmt.Errorf("invalid value for %s: %w", name, err)
+	}
+
+	return nil
+}
+
+// DecodeStructured decodes the given JSON or YAML document into the value pointed to by v. The document is
+// decoded using the JSON field tags of v and unknown fields are rejected.
+func DecodeStructured(data []byte, v interface{}) error {
+	if !json.Valid(data) {
+		var doc interface{}
+
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("decode YAML: %w", err)
+		}
+
+		var err error
+
+		data, err = json.Marshal(doc)
+		if err != nil {
+			return fmt.Errorf("decode YAML: %w", err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("decode JSON: %w", err)
+	}
+
+	return nil
+}
+
+// rawValue returns the name and the unresolved value of the command line flag, if set, or else
+// of the environment variable.
+func rawValue(cmd *cobra.Command, flagName, envKey string) (string, string) {
+	if cmd.Flags().Changed(flagName) {
+		//nolint:errcheck // the flag was already read
+		value, _ := cmd.Flags().GetString(flagName)
+
+		return flagName, value
+	}
+
+	return envKey, os.Getenv(envKey)
+}
@@ -0,0 +1,232 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+const (
+	issuersFlagName = "trusted-issuers"
+	issuersEnvKey   = "TEST_TRUSTED_ISSUERS"
+)
+
+type trustedIssuer struct {
+	ID   string   `json:"id"`
+	URLs []string `json:"urls"`
+}
+
+func TestGetStructured(t *testing.T) {
+	expected := []trustedIssuer{{ID: "did:web:a", URLs: []string{"https://a1", "https://a2"}}, {ID: "did:web:b"}}
+
+	t.Run("JSON flag", func(t *testing.T) {
+		command := newCommand(t, issuersFlags(""), "--trusted-issuers",
+			`[{"id":"did:web:a","urls":["https://a1","https://a2"]},{"id":"did:web:b"}]`)
+
+		var issuers []trustedIssuer
+		require.NoError(t, cmd.GetStructured(command, issuersFlagName, issuersEnvKey, &issuers, false))
+		require.Equal(t, expected, issuers)
+	})
+
+	t.Run("YAML env var", func(t *testing.T) {
+		t.Setenv(issuersEnvKey, "- id: did:web:a\n  urls: [https://a1, https://a2]\n- id: did:web:b\n")
+
+		var issuers []trustedIssuer
+		require.NoError(t, cmd.GetStructured(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey,
+			&issuers, false))
+		require.Equal(t, expected, issuers)
+	})
+
+	t.Run("YAML file", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "issuers.yaml")
+		require.NoError(t, os.WriteFile(file,
+			[]byte("- id: did:web:a\n  urls:\n    - https://a1\n    - https://a2\n- id: did:web:b\n"), 0o600))
+
+		var issuers []trustedIssuer
+		require.NoError(t, cmd.GetStructured(newCommand(t, issuersFlags(""), "--trusted-issuers", "@"+file),
+			issuersFlagName, issuersEnvKey, &issuers, false))
+		require.Equal(t, expected, issuers)
+	})
+
+	t.Run("standard input", func(t *testing.T) {
+		t.Setenv(issuersEnvKey, "@-")
+
+		var issuers []trustedIssuer
+		require.NoError(t, cmd.GetStructured(newCommand(t, issuersFlags(`[{"id":"did:web:b"}]`)), issuersFlagName,
+			issuersEnvKey, &issuers, false))
+		require.Equal(t, []trustedIssuer{{ID: "did:web:b"}}, issuers)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		issuers := []trustedIssuer{{ID: "default"}}
+
+		require.NoError(t, cmd.GetStructured(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey,
+			&issuers, true))
+		require.Equal(t, []trustedIssuer{{ID: "default"}}, issuers)
+
+		err := cmd.GetStructured(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey, &issuers, false)
+		require.EqualError(t, err,
+			"Neither trusted-issuers (command line flag) nor TEST_TRUSTED_ISSUERS (environment variable) have been set.")
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		var issuers []trustedIssuer
+
+		err := cmd.GetStructured(newCommand(t, issuersFlags(""), "--trusted-issuers", `[{"id":"a","name":"b"}]`),
+			issuersFlagName, issuersEnvKey, &issuers, false)
+		require.EqualError(t, err, `invalid value for trusted-issuers: decode JSON: json: unknown field "name"`)
+
+		t.Setenv(issuersEnvKey, "id: [")
+
+		err = cmd.GetStructured(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for TEST_TRUSTED_ISSUERS: decode YAML")
+
+		file := filepath.Join(t.TempDir(), "issuers.yaml")
+		require.NoError(t, os.WriteFile(file, []byte("id: a"), 0o600))
+		t.Setenv(issuersEnvKey, "@"+file)
+
+		err = cmd.GetStructured(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for TEST_TRUSTED_ISSUERS (file "+file+"): decode JSON")
+
+		t.Setenv(issuersEnvKey, "@-")
+
+		err = cmd.GetStructured(newCommand(t, issuersFlags("{}")), issuersFlagName, issuersEnvKey, &issuers, false)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid value for TEST_TRUSTED_ISSUERS (standard input): decode JSON")
+	})
+}
+
+func TestFileValues(t *testing.T) {
+	dir := t.TempDir()
+
+	file := filepath.Join(dir, "password")
+	require.NoError(t, os.WriteFile(file, []byte("secret\n"), 0o600))
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "db-password", EnvKey: "TEST_DB_PASSWORD", AllowFile: true},
+		&cmd.Parameter{FlagName: "cas-url", EnvKey: "TEST_CAS_URL", Type: cmd.StringArrayType, AllowFile: true},
+	))
+
+	resolve := func(t *testing.T, stdin string, args ...string) (*cmd.Config, error) {
+		t.Helper()
+
+		return r.Resolve(newCommand(t, func(command *cobra.Command) error {
+			r.AddFlags(command)
+			command.SetIn(strings.NewReader(stdin))
+
+			return nil
+		}, args...))
+	}
+
+	t.Run("string", func(t *testing.T) {
+		cfg, err := resolve(t, "", "--db-password", "@"+file)
+		require.NoError(t, err)
+		require.Equal(t, "secret", cfg.String("db-password"))
+	})
+
+	t.Run("array", func(t *testing.T) {
+		t.Setenv("TEST_CAS_URL", "a,@"+file+",@@literal")
+
+		cfg, err := resolve(t, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "secret", "@literal"}, cfg.StringArray("cas-url"))
+	})
+
+	t.Run("literal", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "@")
+
+		cfg, err := resolve(t, "")
+		require.NoError(t, err)
+		require.Equal(t, "@", cfg.String("db-password"))
+	})
+
+	t.Run("not allowed", func(t *testing.T) {
+		t.Setenv(issuersEnvKey, "@"+file)
+
+		v, err := cmd.GetString(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey, false)
+		require.NoError(t, err)
+		require.Equal(t, "@"+file, v)
+
+		t.Setenv(issuersEnvKey, "@@literal")
+
+		v, err = cmd.GetString(newCommand(t, issuersFlags("")), issuersFlagName, issuersEnvKey, false)
+		require.NoError(t, err)
+		require.Equal(t, "@@literal", v)
+
+		err = r.Register(&cmd.Parameter{
+			FlagName: "timeout", EnvKey: "TEST_TIMEOUT", Type: cmd.DurationType, AllowFile: true,
+		})
+		require.EqualError(t, err, "loading the value from a file is only supported for string parameters: timeout")
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		t.Setenv("TEST_DB_PASSWORD", "@"+filepath.Join(dir, "missing"))
+
+		_, err := resolve(t, "")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "read TEST_DB_PASSWORD from file "+filepath.Join(dir, "missing"))
+	})
+
+	t.Run("size limit", func(t *testing.T) {
+		large := filepath.Join(dir, "large")
+		require.NoError(t, os.WriteFile(large, make([]byte, cmd.MaxValueFileSize+1), 0o600))
+
+		t.Setenv("TEST_DB_PASSWORD", "@"+large)
+
+		_, err := resolve(t, "")
+		require.EqualError(t, err,
+			"invalid configuration: read TEST_DB_PASSWORD from file "+large+
+				": size exceeds the maximum of 1048576 bytes")
+
+		t.Setenv("TEST_DB_PASSWORD", cmd.StdinValue)
+
+		_, err = resolve(t, strings.Repeat("x", cmd.MaxValueFileSize+1))
+		require.EqualError(t, err,
+			"invalid configuration: read TEST_DB_PASSWORD from standard input: size exceeds the maximum of "+
+				"1048576 bytes")
+	})
+
+	t.Run("encrypted file", func(t *testing.T) {
+		key, err := cmd.GenerateEncryptionKey()
+		require.NoError(t, err)
+
+		encrypted, err := cmd.EncryptValue(key, "secret")
+		require.NoError(t, err)
+
+		encryptedFile := filepath.Join(dir, "encrypted")
+		require.NoError(t, os.WriteFile(encryptedFile, []byte(encrypted), 0o600))
+
+		t.Setenv(cmd.EncryptionKeyFileEnvKey, writeKeyFile(t, base64.StdEncoding.EncodeToString(key)))
+		t.Setenv("TEST_DB_PASSWORD", "@"+encryptedFile)
+
+		cfg, err := resolve(t, "")
+		require.NoError(t, err)
+		require.Equal(t, "secret", cfg.String("db-password"))
+	})
+}
+
+// issuersFlags returns a setup function which adds the issuers flag and sets the standard input to stdin.
+func issuersFlags(stdin string) func(*cobra.Command) error {
+	return func(command *cobra.Command) error {
+		command.Flags().String(issuersFlagName, "", "")
+		command.SetIn(strings.NewReader(stdin))
+
+		return nil
+	}
+}
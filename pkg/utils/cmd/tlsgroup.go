@@ -0,0 +1,109 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Names of the TLS parameters. The flag names and environment variables of a TLSGroup are prefixed
+// with the group prefix (e.g. "tls-certificate" and "ORB_TLS_CERTIFICATE").
+const (
+	TLSSystemCertPoolName = "systemcertpool"
+	TLSCACertsName        = "cacerts"
+	TLSCertificateName    = "certificate"
+	TLSKeyName            = "key"
+)
+
+// TLSGroup is the option group of the TLS parameters retrieved with GetTLS.
+type TLSGroup struct {
+	fields *TLSFields
+	opts   []TLSOption
+}
+
+var _ OptionGroup[*TLSParameters] = (*TLSGroup)(nil)
+
+// NewTLSGroup returns a TLS option group with the given prefix (e.g. "admin-tls-" and "ADMIN_TLS_").
+// The options are passed to GetTLS.
+func NewTLSGroup(prefix GroupPrefix, opts ...TLSOption) *TLSGroup {
+	return NewTLSGroupFromFields(&TLSFields{
+		SystemCertPoolFlagName: prefix.FlagName(TLSSystemCertPoolName),
+		SystemCertPoolEnvKey:   prefix.EnvKey(TLSSystemCertPoolName),
+		CACertsFlagName:        prefix.FlagName(TLSCACertsName),
+		CACertsEnvKey:          prefix.EnvKey(TLSCACertsName),
+		CertificateFlagName:    prefix.FlagName(TLSCertificateName),
+		CertificateLEnvKey:     prefix.EnvKey(TLSCertificateName),
+		KeyFlagName:            prefix.FlagName(TLSKeyName),
+		KeyEnvKey:              prefix.EnvKey(TLSKeyName),
+	}, opts...)
+}
+
+// NewTLSGroupFromFields returns a TLS option group with the given field names.
+func NewTLSGroupFromFields(fields *TLSFields, opts ...TLSOption) *TLSGroup {
+	return &TLSGroup{fields: fields, opts: opts}
+}
+
+// Fields returns the flag names and environment variables of the group.
+func (g *TLSGroup) Fields() *TLSFields {
+	return g.fields
+}
+
+// Parameters returns the TLS parameters.
+func (g *TLSGroup) Parameters() []*Parameter {
+	return []*Parameter{
+		{
+			FlagName:    g.fields.SystemCertPoolFlagName,
+			EnvKey:      g.fields.SystemCertPoolEnvKey,
+			Type:        BoolType,
+			Description: "Use system certificate pool.",
+			Default:     false,
+		},
+		{
+			FlagName:       g.fields.CACertsFlagName,
+			EnvKey:         g.fields.CACertsEnvKey,
+			Type:           StringArrayType,
+			Description:    "Comma-separated list of CA certs path.",
+			Completion:     CompleteFile,
+			FileExtensions: []string{ExtPEM, ExtCRT},
+		},
+		{
+			FlagName:       g.fields.CertificateFlagName,
+			EnvKey:         g.fields.CertificateLEnvKey,
+			Description:    "TLS certificate path.",
+			Completion:     CompleteFile,
+			FileExtensions: []string{ExtPEM, ExtCRT, ExtPKCS12},
+		},
+		{
+			FlagName:       g.fields.KeyFlagName,
+			EnvKey:         g.fields.KeyEnvKey,
+			Description:    "TLS key path.",
+			Completion:     CompleteFile,
+			FileExtensions: []string{ExtPEM, ExtKey, ExtPKCS12},
+		},
+	}
+}
+
+// AddFlags adds the TLS flags to the given command.
+func (g *TLSGroup) AddFlags(cmd *cobra.Command) error {
+	return addGroupFlags(cmd, cmd.Flags(), g.Parameters())
+}
+
+// Resolve resolves the TLS parameters with GetTLS.
+func (g *TLSGroup) Resolve(cmd *cobra.Command) (*TLSParameters, error) {
+	return GetTLS(cmd, g.fields, g.opts...)
+}
+
+// Validate verifies that the TLS certificate and key are set together.
+func (g *TLSGroup) Validate(params *TLSParameters) error {
+	if (params.ServeCertPath == "") != (params.ServeKeyPath == "") {
+		return fmt.Errorf("%s (%s) and %s (%s) must be set together", g.fields.CertificateFlagName,
+			g.fields.CertificateLEnvKey, g.fields.KeyFlagName, g.fields.KeyEnvKey)
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestTLSGroup(t *testing.T) {
+	publicTLS := cmd.NewTLSGroup(cmd.GroupPrefix{Flag: "tls-", Env: "ORB_TLS_"})
+	adminTLS := cmd.NewTLSGroup(cmd.GroupPrefix{Flag: "admin-tls-", Env: "ORB_ADMIN_TLS_"}, cmd.WithStrictTLS())
+
+	require.Equal(t, &cmd.TLSFields{
+		SystemCertPoolFlagName: "admin-tls-systemcertpool",
+		SystemCertPoolEnvKey:   "ORB_ADMIN_TLS_SYSTEMCERTPOOL",
+		CACertsFlagName:        "admin-tls-cacerts",
+		CACertsEnvKey:          "ORB_ADMIN_TLS_CACERTS",
+		CertificateFlagName:    "admin-tls-certificate",
+		CertificateLEnvKey:     "ORB_ADMIN_TLS_CERTIFICATE",
+		KeyFlagName:            "admin-tls-key",
+		KeyEnvKey:              "ORB_ADMIN_TLS_KEY",
+	}, adminTLS.Fields())
+
+	command := &cobra.Command{Use: "start", Run: func(*cobra.Command, []string) {}}
+	require.NoError(t, publicTLS.AddFlags(command))
+	require.NoError(t, adminTLS.AddFlags(command))
+
+	for _, name := range []string{"tls-systemcertpool", "tls-cacerts", "tls-certificate", "tls-key",
+		"admin-tls-systemcertpool", "admin-tls-cacerts", "admin-tls-certificate", "admin-tls-key"} {
+		require.NotNil(t, command.Flags().Lookup(name), name)
+	}
+
+	require.Contains(t, command.Flags().Lookup("admin-tls-key").Usage,
+		"TLS key path. Alternatively, this can be set with the following environment variable: ORB_ADMIN_TLS_KEY")
+	require.Equal(t, "stringArray", command.Flags().Lookup("tls-cacerts").Value.Type())
+
+	certPath, keyPath := writeTestKeyPair(t)
+
+	t.Setenv("ORB_ADMIN_TLS_CACERTS", certPath)
+
+	command.SetArgs([]string{"--tls-systemcertpool", "true", "--tls-certificate", certPath, "--tls-key", keyPath})
+	require.NoError(t, command.Execute())
+
+	params, err := cmd.ResolveGroup[*cmd.TLSParameters](command, publicTLS)
+	require.NoError(t, err)
+	require.Equal(t, &cmd.TLSParameters{
+		SystemCertPool: true, CACerts: []string{}, ServeCertPath: certPath, ServeKeyPath: keyPath,
+	}, params)
+
+	params, err = cmd.ResolveGroup[*cmd.TLSParameters](command, adminTLS)
+	require.NoError(t, err)
+	require.Equal(t, &cmd.TLSParameters{CACerts: []string{certPath}}, params)
+
+	t.Run("validation", func(t *testing.T) {
+		t.Setenv("ORB_TLS_KEY", keyPath)
+
+		_, err := cmd.ResolveGroup[*cmd.TLSParameters](&cobra.Command{}, publicTLS)
+		require.EqualError(t, err,
+			"tls-certificate (ORB_TLS_CERTIFICATE) and tls-key (ORB_TLS_KEY) must be set together")
+
+		t.Setenv("ORB_ADMIN_TLS_CACERTS", "missing.pem")
+
+		_, err = cmd.ResolveGroup[*cmd.TLSParameters](&cobra.Command{}, adminTLS)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "admin-tls-cacerts (ORB_ADMIN_TLS_CACERTS): CA cert missing.pem")
+	})
+
+	t.Run("from fields", func(t *testing.T) {
+		group := cmd.NewTLSGroupFromFields(newTestTLSFields())
+		require.Equal(t, newTestTLSFields(), group.Fields())
+		require.Len(t, group.Parameters(), 4)
+		require.Equal(t, cmd.BoolType, group.Parameters()[0].Type)
+	})
+
+	t.Run("empty field names", func(t *testing.T) {
+		group := cmd.NewTLSGroupFromFields(&cmd.TLSFields{
+			CACertsFlagName: "ca-certs", CACertsEnvKey: "TEST_CA_CERTS",
+			KeyFlagName: "key", KeyEnvKey: "TEST_KEY",
+		})
+
+		c := &cobra.Command{Use: "start"}
+		require.NoError(t, group.AddFlags(c))
+		require.NotNil(t, c.Flags().Lookup("ca-certs"))
+		require.NotNil(t, c.Flags().Lookup("key"))
+	})
+
+	t.Run("invalid field", func(t *testing.T) {
+		group := cmd.NewTLSGroupFromFields(&cmd.TLSFields{CACertsFlagName: "ca-certs", KeyFlagName: "key"})
+
+		err := group.AddFlags(&cobra.Command{Use: "start"})
+		require.EqualError(t, err, "environment variable for parameter ca-certs is empty")
+
+		configCmd := cmd.NewRegistry().NewConfigCommand(cmd.WithTLS(&cmd.TLSFields{
+			CACertsFlagName: "ca-certs", KeyFlagName: "key",
+		}))
+		configCmd.SetArgs([]string{"validate"})
+		configCmd.SetOut(&bytes.Buffer{})
+		configCmd.SetErr(&bytes.Buffer{})
+
+		require.EqualError(t, configCmd.Execute(), "environment variable for parameter ca-certs is empty")
+	})
+}
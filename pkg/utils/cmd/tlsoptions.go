@@ -0,0 +1,153 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/spf13/cobra"
+
+	tlsutil "github.com/trustbloc/cmdutil-go/pkg/utils/tls"
+)
+
+type tlsOptions struct {
+	strict             bool
+	requireCACerts     bool
+	requireCertificate bool
+}
+
+// TLSOption is an option for GetTLS.
+type TLSOption func(opts *tlsOptions)
+
+// WithStrictTLS verifies that every CA cert and the serving cert and key files exist and are readable, that
+// the serving cert and key are set together and that they match. All problems are returned together and
+// identified with the flag and environment variable names from TLSFields.
+func WithStrictTLS() TLSOption {
+	return func(opts *tlsOptions) {
+		opts.strict = true
+	}
+}
+
+// WithRequiredCACerts marks the CA certs as required. It implies WithStrictTLS.
+func WithRequiredCACerts() TLSOption {
+	return func(opts *tlsOptions) {
+		opts.strict = true
+		opts.requireCACerts = true
+	}
+}
+
+// WithRequiredCertificate marks the serving cert and key as required. It implies WithStrictTLS.
+func WithRequiredCertificate() TLSOption {
+	return func(opts *tlsOptions) {
+		opts.strict = true
+		opts.requireCertificate = true
+	}
+}
+
+func getStrictTLS(cmd *cobra.Command, fields *TLSFields, options *tlsOptions) (*TLSParameters, error) {
+	var errs []error
+
+	params := &TLSParameters{}
+
+	systemCertPool, err := GetUserSetVarFromString(cmd, fields.SystemCertPoolFlagName,
+		fields.SystemCertPoolEnvKey, true)
+	if err != nil {
+		errs = append(errs, tlsFieldError(fields.SystemCertPoolFlagName, fields.SystemCertPoolEnvKey, err))
+	} else if systemCertPool != "" {
+		params.SystemCertPool, err = strconv.ParseBool(systemCertPool)
+		if err != nil {
+			errs = append(errs, tlsFieldError(fields.SystemCertPoolFlagName, fields.SystemCertPoolEnvKey, err))
+		}
+	}
+
+	params.CACerts, err = GetUserSetVarFromArrayString(cmd, fields.CACertsFlagName, fields.CACertsEnvKey,
+		!options.requireCACerts)
+	if err != nil {
+		errs = append(errs, tlsFieldError(fields.CACertsFlagName, fields.CACertsEnvKey, err))
+	}
+
+	for _, caCert := range params.CACerts {
+		if _, err := tlsutil.ParseCACertFile(caCert); err != nil {
+			errs = append(errs, tlsFieldError(fields.CACertsFlagName, fields.CACertsEnvKey,
+				fmt.Errorf("CA cert %s: %w", caCert, err)))
+		}
+	}
+
+	params.ServeCertPath, err = GetUserSetVarFromString(cmd, fields.CertificateFlagName, fields.CertificateLEnvKey,
+		!options.requireCertificate)
+	if err != nil {
+		errs = append(errs, tlsFieldError(fields.CertificateFlagName, fields.CertificateLEnvKey, err))
+	}
+
+	params.ServeKeyPath, err = GetUserSetVarFromString(cmd, fields.KeyFlagName, fields.KeyEnvKey,
+		!options.requireCertificate)
+	if err != nil {
+		errs = append(errs, tlsFieldError(fields.KeyFlagName, fields.KeyEnvKey, err))
+	}
+
+	errs = append(errs, verifyKeyPair(fields, params)...)
+
+	if len(errs) > 0 {
+		return nil, &ValidationError{Errors: errs}
+	}
+
+	return params, nil
+}
+
+// verifyKeyPair verifies that the serving cert and key are set together, that both files are readable
+// and that they match.
+func verifyKeyPair(fields *TLSFields, params *TLSParameters) []error {
+	certPath, keyPath := params.ServeCertPath, params.ServeKeyPath
+
+	switch {
+	case certPath == "" && keyPath == "":
+		return nil
+	case certPath == "":
+		return []error{tlsFieldError(fields.CertificateFlagName, fields.CertificateLEnvKey,
+			fmt.Errorf("must be set since %s (%s) is set", fields.KeyFlagName, fields.KeyEnvKey))}
+	case keyPath == "":
+		return []error{tlsFieldError(fields.KeyFlagName, fields.KeyEnvKey,
+			fmt.Errorf("must be set since %s (%s) is set", fields.CertificateFlagName, fields.CertificateLEnvKey))}
+	}
+
+	var errs []error
+
+	if err := checkReadable(certPath); err != nil {
+		errs = append(errs, tlsFieldError(fields.CertificateFlagName, fields.CertificateLEnvKey, err))
+	}
+
+	if err := checkReadable(keyPath); err != nil {
+		errs = append(errs, tlsFieldError(fields.KeyFlagName, fields.KeyEnvKey, err))
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if _, err := tls.LoadX509KeyPair(certPath, keyPath); err != nil {
+		return []error{fmt.Errorf("%s (%s) and %s (%s): invalid key pair: %w", fields.CertificateFlagName,
+			fields.CertificateLEnvKey, fields.KeyFlagName, fields.KeyEnvKey, err)}
+	}
+
+	return nil
+}
+
+func checkReadable(path string) error {
+	f, err := os.Open(filepath.Clean(path))
+	if err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+func tlsFieldError(flagName, envKey string, err error) error {
+	return fmt.Errorf("%s (%s): %w", flagName, envKey, err)
+}
@@ -0,0 +1,150 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestGetTLS_Strict(t *testing.T) {
+	certPath, keyPath := writeTestKeyPair(t)
+
+	t.Run("success", func(t *testing.T) {
+		t.Setenv("TEST_TLS_SYSTEMCERTPOOL", "true")
+		t.Setenv("TEST_TLS_CACERTS", certPath+","+certPath)
+		t.Setenv("TEST_TLS_CERTIFICATE", certPath)
+		t.Setenv("TEST_TLS_KEY", keyPath)
+
+		params, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithRequiredCACerts(),
+			cmd.WithRequiredCertificate())
+		require.NoError(t, err)
+		require.Equal(t, &cmd.TLSParameters{
+			SystemCertPool: true,
+			CACerts:        []string{certPath, certPath},
+			ServeCertPath:  certPath,
+			ServeKeyPath:   keyPath,
+		}, params)
+	})
+
+	t.Run("not set", func(t *testing.T) {
+		params, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithStrictTLS())
+		require.NoError(t, err)
+		require.Equal(t, &cmd.TLSParameters{CACerts: []string{}}, params)
+	})
+
+	t.Run("required", func(t *testing.T) {
+		_, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithRequiredCACerts(), cmd.WithRequiredCertificate())
+
+		var validationErr *cmd.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errors, 3)
+		require.EqualError(t, validationErr.Errors[0], "tls-cacerts (TEST_TLS_CACERTS): Neither tls-cacerts "+
+			"(command line flag) nor TEST_TLS_CACERTS (environment variable) have been set.")
+		require.Contains(t, validationErr.Errors[1].Error(), "tls-certificate (TEST_TLS_CERTIFICATE): Neither")
+		require.Contains(t, validationErr.Errors[2].Error(), "tls-key (TEST_TLS_KEY): Neither")
+	})
+
+	t.Run("all problems are returned", func(t *testing.T) {
+		missing := filepath.Join(t.TempDir(), "missing.pem")
+
+		invalid := filepath.Join(t.TempDir(), "invalid.pem")
+		require.NoError(t, os.WriteFile(invalid, []byte("invalid"), 0o600))
+
+		t.Setenv("TEST_TLS_SYSTEMCERTPOOL", "maybe")
+		t.Setenv("TEST_TLS_CACERTS", certPath+","+missing+","+invalid)
+		t.Setenv("TEST_TLS_CERTIFICATE", missing)
+		t.Setenv("TEST_TLS_KEY", keyPath)
+
+		_, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithStrictTLS())
+
+		var validationErr *cmd.ValidationError
+		require.ErrorAs(t, err, &validationErr)
+		require.Len(t, validationErr.Errors, 4)
+		require.Contains(t, validationErr.Errors[0].Error(), "tls-systemcertpool (TEST_TLS_SYSTEMCERTPOOL): ")
+		require.Contains(t, validationErr.Errors[1].Error(),
+			"tls-cacerts (TEST_TLS_CACERTS): CA cert "+missing+": failed to read cert")
+		require.Contains(t, validationErr.Errors[2].Error(),
+			"tls-cacerts (TEST_TLS_CACERTS): CA cert "+invalid+": failed to decode pem")
+		require.Contains(t, validationErr.Errors[3].Error(), "tls-certificate (TEST_TLS_CERTIFICATE): open "+missing)
+	})
+
+	t.Run("missing cert or key", func(t *testing.T) {
+		t.Setenv("TEST_TLS_KEY", filepath.Join(t.TempDir(), "missing.pem"))
+
+		_, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithStrictTLS())
+		require.EqualError(t, err, "invalid configuration: tls-certificate (TEST_TLS_CERTIFICATE): "+
+			"must be set since tls-key (TEST_TLS_KEY) is set")
+
+		t.Setenv("TEST_TLS_CERTIFICATE", certPath)
+
+		_, err = cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithStrictTLS())
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "tls-key (TEST_TLS_KEY): open")
+	})
+
+	t.Run("key pair mismatch", func(t *testing.T) {
+		_, otherKeyPath := writeTestKeyPair(t)
+
+		t.Setenv("TEST_TLS_CERTIFICATE", certPath)
+		t.Setenv("TEST_TLS_KEY", otherKeyPath)
+
+		_, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithStrictTLS())
+		require.Error(t, err)
+		require.Contains(t, err.Error(),
+			"tls-certificate (TEST_TLS_CERTIFICATE) and tls-key (TEST_TLS_KEY): invalid key pair")
+	})
+
+	t.Run("resolve", func(t *testing.T) {
+		t.Setenv("TEST_TLS_CERTIFICATE", certPath)
+
+		_, err := cmd.NewRegistry().Resolve(&cobra.Command{}, cmd.WithTLS(newTestTLSFields(), cmd.WithStrictTLS()))
+		require.EqualError(t, err, "invalid configuration: tls-key (TEST_TLS_KEY): "+
+			"must be set since tls-certificate (TEST_TLS_CERTIFICATE) is set")
+	})
+}
+
+func TestGetTLS_ResolutionErrors(t *testing.T) {
+	t.Setenv(cmd.EncryptionKeyEnvKey, "")
+	t.Setenv(cmd.EncryptionKeyFileEnvKey, "")
+
+	for _, field := range []struct{ flagName, envKey string }{
+		{"tls-systemcertpool", "TEST_TLS_SYSTEMCERTPOOL"},
+		{"tls-cacerts", "TEST_TLS_CACERTS"},
+		{"tls-certificate", "TEST_TLS_CERTIFICATE"},
+		{"tls-key", "TEST_TLS_KEY"},
+	} {
+		t.Run(field.envKey, func(t *testing.T) {
+			t.Setenv(field.envKey, cmd.EncryptedValuePrefix+"invalid")
+
+			// As with the Optional getters, values which can't be resolved are ignored unless strict.
+			params, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields())
+			require.NoError(t, err)
+			require.False(t, params.SystemCertPool)
+			require.Empty(t, params.CACerts)
+			require.Empty(t, params.ServeCertPath)
+			require.Empty(t, params.ServeKeyPath)
+
+			_, err = cmd.GetTLS(&cobra.Command{}, newTestTLSFields(), cmd.WithStrictTLS())
+			require.Error(t, err)
+			require.Contains(t, err.Error(), field.flagName+" ("+field.envKey+"): decrypt "+field.envKey)
+		})
+	}
+
+	t.Run("invalid system cert pool", func(t *testing.T) {
+		t.Setenv("TEST_TLS_SYSTEMCERTPOOL", "maybe")
+
+		_, err := cmd.GetTLS(&cobra.Command{}, newTestTLSFields())
+		require.EqualError(t, err, `tls-systemcertpool (TEST_TLS_SYSTEMCERTPOOL): strconv.ParseBool: parsing "maybe": `+
+			"invalid syntax")
+	})
+}
@@ -0,0 +1,240 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/trustbloc/logutil-go/pkg/log"
+
+	"github.com/trustbloc/cmdutil-go/internal/logfields"
+)
+
+var logger = log.New("cmdutil-go-cmd")
+
+// UnknownKeyPolicy defines how unknown configuration keys are handled.
+type UnknownKeyPolicy int
+
+const (
+	// UnknownKeyIgnore ignores unknown keys.
+	UnknownKeyIgnore UnknownKeyPolicy = iota
+	// UnknownKeyWarn logs a warning for each unknown key.
+	UnknownKeyWarn
+	// UnknownKeyFail returns an UnknownKeysError if there are unknown keys.
+	UnknownKeyFail
+)
+
+// UnknownKey contains a configuration key which doesn't match any registered parameter
+// along with the closest registered key (if any).
+type UnknownKey struct {
+	Key        string
+	Suggestion string
+}
+
+// String returns a description of the unknown key including the suggestion.
+func (k UnknownKey) String() string {
+	if k.Suggestion == "" {
+		return k.Key
+	}
+
+	return fmt.Sprintf("%s (did you mean %s?)", k.Key, k.Suggestion)
+}
+
+// UnknownKeysError is returned when unknown configuration keys are found and the policy is UnknownKeyFail.
+type UnknownKeysError struct {
+	Keys []UnknownKey
+}
+
+// Error returns the error message.
+func (e *UnknownKeysError) Error() string {
+	keys := make([]string, len(e.Keys))
+
+	for i, k := range e.Keys {
+		keys[i] = k.String()
+	}
+
+	return "unknown configuration keys: " + strings.Join(keys, ", ")
+}
+
+// FindUnknownEnvKeys returns the environment variables in the given environment (in the form "key=value",
+// as returned by os.Environ) which start with the given prefix but don't match any registered parameter.
+// The indexed and nested variables of Nested parameters (see GetNested) are known.
+func (r *Registry) FindUnknownEnvKeys(prefix string, environ []string) []UnknownKey {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var candidates []string
+
+	for envKey := range r.byEnv {
+		candidates = append(candidates, envKey)
+	}
+
+	var unknown []UnknownKey
+
+	for _, kv := range environ {
+		key := strings.SplitN(kv, "=", 2)[0] //nolint:gomnd // key and value
+
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		if _, ok := r.byEnv[key]; ok || r.isNestedEnvKey(key) {
+			continue
+		}
+
+		unknown = append(unknown, UnknownKey{Key: key, Suggestion: suggest(key, candidates)})
+	}
+
+	sortUnknownKeys(unknown)
+
+	return unknown
+}
+
+// isNestedEnvKey returns true if key sets an element or a field of a Nested parameter.
+func (r *Registry) isNestedEnvKey(key string) bool {
+	for _, p := range r.params {
+		if p.Nested && (isNestedEnvKey(key, p.EnvKey, true) || isNestedEnvKey(key, p.EnvKey, false)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindUnknownKeys returns the given configuration file keys which don't match the flag name of
+// any registered parameter.
+func (r *Registry) FindUnknownKeys(keys []string) []UnknownKey {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	var candidates []string
+
+	for flagName := range r.byFlag {
+		candidates = append(candidates, flagName)
+	}
+
+	var unknown []UnknownKey
+
+	for _, key := range keys {
+		if _, ok := r.byFlag[key]; ok {
+			continue
+		}
+
+		unknown = append(unknown, UnknownKey{Key: key, Suggestion: suggest(key, candidates)})
+	}
+
+	sortUnknownKeys(unknown)
+
+	return unknown
+}
+
+// CheckEnv checks the process environment for variables with the given prefix which don't match
+// any registered parameter and handles them according to the given policy.
+func (r *Registry) CheckEnv(prefix string, policy UnknownKeyPolicy) error {
+	return handleUnknownKeys(r.FindUnknownEnvKeys(prefix, os.Environ()), policy)
+}
+
+// CheckKeys checks the given configuration file keys for keys which don't match any registered
+// parameter and handles them according to the given policy.
+func (r *Registry) CheckKeys(keys []string, policy UnknownKeyPolicy) error {
+	return handleUnknownKeys(r.FindUnknownKeys(keys), policy)
+}
+
+func handleUnknownKeys(unknown []UnknownKey, policy UnknownKeyPolicy) error {
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	switch policy {
+	case UnknownKeyIgnore:
+		return nil
+	case UnknownKeyWarn:
+		for _, k := range unknown {
+			logger.Warn("Unknown configuration key", logfields.WithConfigKey(k.Key),
+				logfields.WithSuggestion(k.Suggestion))
+		}
+
+		return nil
+	case UnknownKeyFail:
+		return &UnknownKeysError{Keys: unknown}
+	default:
+		return fmt.Errorf("unsupported unknown key policy: %d", policy)
+	}
+}
+
+func sortUnknownKeys(keys []UnknownKey) {
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Key < keys[j].Key
+	})
+}
+
+// suggest returns the candidate with the smallest edit distance to the given key, provided that
+// the distance is small enough for the candidate to be a likely misspelling of the key.
+func suggest(key string, candidates []string) string {
+	maxDistance := len(key) / 4 //nolint:gomnd // allow one edit per four characters
+
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	suggestion := ""
+	best := maxDistance + 1
+
+	for _, c := range candidates {
+		d := editDistance(strings.ToLower(key), strings.ToLower(c))
+
+		if d < best || (d == best && c < suggestion) {
+			suggestion = c
+			best = d
+		}
+	}
+
+	if best > maxDistance {
+		return ""
+	}
+
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between the given strings.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+
+			curr[j] = minInt(minInt(prev[j]+1, curr[j-1]+1), prev[j-1]+cost)
+		}
+
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
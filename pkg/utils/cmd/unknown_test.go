@@ -0,0 +1,129 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/trustbloc/cmdutil-go/pkg/utils/cmd"
+)
+
+func TestRegistry_FindUnknownEnvKeys(t *testing.T) {
+	r := newUnknownKeysRegistry(t)
+
+	unknown := r.FindUnknownEnvKeys("ORB_", []string{
+		"ORB_HOST_URL=localhost:8080",
+		"ORB_HOST_ULR=localhost:8080",
+		"ORB_DATABASE_TPYE=mongodb",
+		"ORB_SOMETHING_ELSE=value",
+		"PATH=/usr/bin",
+	})
+
+	require.Equal(t, []cmd.UnknownKey{
+		{Key: "ORB_DATABASE_TPYE", Suggestion: "ORB_DATABASE_TYPE"},
+		{Key: "ORB_HOST_ULR", Suggestion: "ORB_HOST_URL"},
+		{Key: "ORB_SOMETHING_ELSE"},
+	}, unknown)
+}
+
+func TestRegistry_FindUnknownEnvKeys_Nested(t *testing.T) {
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "issuer", EnvKey: "ORB_ISSUERS", Type: cmd.StringArrayType, Nested: true},
+		&cmd.Parameter{FlagName: "db", EnvKey: "ORB_DB", Nested: true},
+	))
+
+	unknown := r.FindUnknownEnvKeys("ORB_", []string{
+		"ORB_ISSUERS_0_NAME=a",
+		"ORB_ISSUERS_1_POOL__MAX_SIZE=10",
+		"ORB_DB__URL=mongodb://localhost",
+		"ORB_ISSUERS_NAME=a",
+		"ORB_DB_URL=mongodb://localhost",
+	})
+
+	require.Equal(t, []cmd.UnknownKey{
+		{Key: "ORB_DB_URL"},
+		{Key: "ORB_ISSUERS_NAME"},
+	}, unknown)
+
+	err := r.Register(&cmd.Parameter{FlagName: "x", EnvKey: "ORB_X", Type: cmd.BoolType, Nested: true})
+	require.EqualError(t, err, "nested values are only supported for string and string array parameters: x")
+}
+
+func TestRegistry_FindUnknownKeys(t *testing.T) {
+	r := newUnknownKeysRegistry(t)
+
+	unknown := r.FindUnknownKeys([]string{"host-url", "host-ulr", "databse-type", "x"})
+
+	require.Equal(t, []cmd.UnknownKey{
+		{Key: "databse-type", Suggestion: "database-type"},
+		{Key: "host-ulr", Suggestion: "host-url"},
+		{Key: "x"},
+	}, unknown)
+}
+
+func TestRegistry_CheckEnv(t *testing.T) {
+	r := newUnknownKeysRegistry(t)
+
+	t.Setenv("ORB_HOST_URL", "localhost:8080")
+
+	t.Run("no unknown keys", func(t *testing.T) {
+		require.NoError(t, r.CheckEnv("ORB_", cmd.UnknownKeyFail))
+	})
+
+	t.Setenv("ORB_HOST_ULR", "localhost:8080")
+
+	t.Run("ignore", func(t *testing.T) {
+		require.NoError(t, r.CheckEnv("ORB_", cmd.UnknownKeyIgnore))
+	})
+
+	t.Run("warn", func(t *testing.T) {
+		require.NoError(t, r.CheckEnv("ORB_", cmd.UnknownKeyWarn))
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		err := r.CheckEnv("ORB_", cmd.UnknownKeyFail)
+		require.Error(t, err)
+		require.EqualError(t, err, "unknown configuration keys: ORB_HOST_ULR (did you mean ORB_HOST_URL?)")
+
+		var unknownErr *cmd.UnknownKeysError
+		require.True(t, errors.As(err, &unknownErr))
+		require.Len(t, unknownErr.Keys, 1)
+	})
+
+	t.Run("invalid policy", func(t *testing.T) {
+		err := r.CheckEnv("ORB_", cmd.UnknownKeyPolicy(100))
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unsupported unknown key policy")
+	})
+}
+
+func TestRegistry_CheckKeys(t *testing.T) {
+	r := newUnknownKeysRegistry(t)
+
+	require.NoError(t, r.CheckKeys([]string{"host-url"}, cmd.UnknownKeyFail))
+	require.NoError(t, r.CheckKeys([]string{"host-ulr"}, cmd.UnknownKeyWarn))
+
+	err := r.CheckKeys([]string{"host-ulr", "other"}, cmd.UnknownKeyFail)
+	require.EqualError(t, err, "unknown configuration keys: host-ulr (did you mean host-url?), other")
+}
+
+func newUnknownKeysRegistry(t *testing.T) *cmd.Registry {
+	t.Helper()
+
+	r := cmd.NewRegistry()
+
+	require.NoError(t, r.Register(
+		&cmd.Parameter{FlagName: "host-url", EnvKey: "ORB_HOST_URL"},
+		&cmd.Parameter{FlagName: "database-type", EnvKey: "ORB_DATABASE_TYPE"},
+	))
+
+	return r
+}
@@ -24,31 +24,7 @@ func GetUserSetOptionalVarFromString(cmd *cobra.Command, flagName, envKey string
 
 // GetUserSetVarFromString returns values either command line flag or environment variable.
 func GetUserSetVarFromString(cmd *cobra.Command, flagName, envKey string, isOptional bool) (string, error) {
-	if cmd.Flags().Changed(flagName) {
-		value, err := cmd.Flags().GetString(flagName)
-		if err != nil {
-			return "", fmt.Errorf(flagName+" flag not found: %s", err)
-		}
-
-		if value == "" {
-			return "", fmt.Errorf("%s value is empty", flagName)
-		}
-
-		return value, nil
-	}
-
-	value, isSet := os.LookupEnv(envKey)
-
-	if isOptional || isSet {
-		if !isOptional && value == "" {
-			return "", fmt.Errorf("%s value is empty", envKey)
-		}
-
-		return value, nil
-	}
-
-	return "", errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
-		" (environment variable) have been set.")
+	return getString(cmd, flagName, envKey, isOptional, resolveValue)
 }
 
 // GetUserSetOptionalVarFromArrayString returns the variables set via either command line flag or environment variable.
@@ -71,35 +47,7 @@ func GetUserSetOptionalVarFromArrayString(cmd *cobra.Command, flagName, envKey s
 // The command line flag must be set as a StringArray.
 // If the variable isn't set, then an error will be returned.
 func GetUserSetVarFromArrayString(cmd *cobra.Command, flagName, envKey string, isOptional bool) ([]string, error) {
-	if cmd.Flags().Changed(flagName) {
-		value, err := cmd.Flags().GetStringArray(flagName)
-		if err != nil {
-			return nil, fmt.Errorf(flagName+" flag not found: %s", err)
-		}
-
-		if len(value) == 0 {
-			return nil, fmt.Errorf("%s value is empty", flagName)
-		}
-
-		return value, nil
-	}
-
-	value, isSet := os.LookupEnv(envKey)
-
-	if isOptional || isSet {
-		if !isOptional && value == "" {
-			return nil, fmt.Errorf("%s value is empty", envKey)
-		}
-
-		if value == "" {
-			return []string{}, nil
-		}
-
-		return strings.Split(value, ","), nil
-	}
-
-	return nil, errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
-		" (environment variable) have been set.")
+	return getStringArray(cmd, flagName, envKey, isOptional, resolveValue)
 }
 
 // GetUserSetOptionalCSVVar returns the variables set via either command line flag or environment variable.
@@ -120,6 +68,12 @@ func GetUserSetOptionalCSVVar(cmd *cobra.Command, flagName, envKey string) []str
 // The command line flag must be set as a StringSlice.
 // If the variable isn't set, then an error will be returned.
 func GetUserSetCSVVar(cmd *cobra.Command, flagName, envKey string, isOptional bool) ([]string, error) {
+	return getCSV(cmd, flagName, envKey, isOptional, resolveValue)
+}
+
+// getCSV returns the comma-separated values set via either command line flag or environment variable resolved
+// with the given resolver.
+func getCSV(cmd *cobra.Command, flagName, envKey string, isOptional bool, resolve valueResolver) ([]string, error) {
 	if cmd.Flags().Changed(flagName) {
 		value, err := cmd.Flags().GetStringSlice(flagName)
 		if err != nil {
@@ -130,7 +84,7 @@ func GetUserSetCSVVar(cmd *cobra.Command, flagName, envKey string, isOptional bo
 			return nil, fmt.Errorf("%s value is empty", flagName)
 		}
 
-		return value, nil
+		return resolveValues(resolve, cmd, flagName, value)
 	}
 
 	value, isSet := os.LookupEnv(envKey)
@@ -144,7 +98,7 @@ func GetUserSetCSVVar(cmd *cobra.Command, flagName, envKey string, isOptional bo
 			return nil, nil
 		}
 
-		return strings.Split(value, ","), nil
+		return resolveValues(resolve, cmd, envKey, strings.Split(value, ","))
 	}
 
 	return nil, errors.New("Neither " + flagName + " (command line flag) nor " + envKey +
@@ -264,3 +264,26 @@ func TestGetUserSetCSVVar(t *testing.T) {
 	env = cmd.GetUserSetOptionalCSVVar(command, flagName, "")
 	require.Equal(t, []string{"other", "other1"}, env)
 }
+
+// newCommand returns a "start" command which is set up with setup (e.g. to add its flags) and executed with the
+// given arguments.
+func newCommand(t *testing.T, setup func(*cobra.Command) error, args ...string) *cobra.Command {
+	t.Helper()
+
+	command := &cobra.Command{Use: "start", Run: func(*cobra.Command, []string) {}}
+	require.NoError(t, setup(command))
+	command.SetArgs(args)
+
+	require.NoError(t, command.Execute())
+
+	return command
+}
+
+// registryFlags returns a setup function which adds the flags of the given registry.
+func registryFlags(r *cmd.Registry) func(*cobra.Command) error {
+	return func(command *cobra.Command) error {
+		r.AddFlags(command)
+
+		return nil
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright SecureKey Technologies Inc. All Rights Reserved.
+SPDX-License-Identifier: Apache-2.0
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// FileValuePrefix is the prefix of a value which is loaded from a file (e.g. "@/etc/orb/issuers.json") by
+	// GetStructured, GetNested and parameters with Parameter.AllowFile. The value "@-" is read from standard
+	// input and a value starting with "@@" is taken literally without the first "@".
+	FileValuePrefix = "@"
+	// StdinValue is the value which is read from standard input.
+	StdinValue = FileValuePrefix + "-"
+	// MaxValueFileSize is the maximum size in bytes of a value loaded from a file or standard input.
+	MaxValueFileSize = 1 << 20
+)
+
+// valueResolver resolves the raw value of a command line flag or environment variable with the given name.
+type valueResolver func(cmd *cobra.Command, name, value string) (string, error)
+
+// resolveValue resolves the raw value of a command line flag or environment variable with the given name:
+// encrypted values are decrypted.
+func resolveValue(_ *cobra.Command, name, value string) (string, error) {
+	if IsEncryptedValue(value) {
+		key, err := LoadEncryptionKey()
+		if err != nil {
+			return "", fmt.Errorf("decrypt %s: %w", name, err)
+		}
+
+		decrypted, err := DecryptValue(key, value)
+		if err != nil {
+			return "", fmt.Errorf("decrypt %s: %w", name, err)
+		}
+
+		return decrypted, nil
+	}
+
+	return value, nil
+}
+
+// resolveFileValue resolves the raw value of a parameter which may be loaded from a file: values prefixed
+// with "@" are loaded from the file (or standard input) and a value prefixed with "@@" is taken literally
+// without the first "@". The value is then resolved with resolveValue, so the contents of a file are never
+// run as a secret provider.
+func resolveFileValue(cmd *cobra.Command, name, value string) (string, error) {
+	if path, ok := valueFile(value); ok {
+		var err error
+
+		value, err = readValueFile(cmd, name, path)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		value = unescapeFileValue(value)
+	}
+
+	return resolveValue(cmd, name, value)
+}
+
+// resolveExecValue resolves the raw value of a parameter which may be obtained from a secret provider: values
+// prefixed with "exec:" are replaced with the output of the command, which is cached in the given cache. The
+// value is then resolved with resolveValue.
+func resolveExecValue(cmd *cobra.Command, cache *execCache, name, value string) (string, error) {
+	if IsExecValue(value) {
+		var err error
+
+		value, err = execValue(cmd, cache, name, value)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return resolveValue(cmd, name, value)
+}
+
+// resolveWith resolves the given raw value with the given resolver or returns it as is if the resolver is nil.
+func resolveWith(resolve valueResolver, cmd *cobra.Command, name, value string) (string, error) {
+	if resolve == nil {
+		return value, nil
+	}
+
+	return resolve(cmd, name, value)
+}
+
+// resolveValues resolves each of the given raw values of a command line flag or environment variable with
+// the given resolver.
+func resolveValues(resolve valueResolver, cmd *cobra.Command, name string, values []string) ([]string, error) {
+	resolved := make([]string, len(values))
+
+	for i, v := range values {
+		r, err := resolveWith(resolve, cmd, name, v)
+		if err != nil {
+			return nil, err
+		}
+
+		resolved[i] = r
+	}
+
+	return resolved, nil
+}
+
+// readValueFile returns the contents of the given file ("-" for standard input). Trailing line breaks are
+// removed.
+func readValueFile(cmd *cobra.Command, name, path string) (string, error) {
+	var (
+		r      io.Reader
+		source = "standard input"
+	)
+
+	if path == "-" {
+		r = stdinOf(cmd)
+	} else {
+		source = "file " + path
+
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return "", fmt.Errorf("read %s from %s: %w", name, source, err)
+		}
+
+		defer f.Close() //nolint:errcheck // read-only file
+
+		r = f
+	}
+
+	b, err := io.ReadAll(io.LimitReader(r, MaxValueFileSize+1))
+	if err != nil {
+		return "", fmt.Errorf("read %s from %s: %w", name, source, err)
+	}
+
+	if len(b) > MaxValueFileSize {
+		return "", fmt.Errorf("read %s from %s: size exceeds the maximum of %d bytes", name, source,
+			MaxValueFileSize)
+	}
+
+	return strings.TrimRight(string(b), "\r\n"), nil
+}
+
+// valueFile returns the path of the file referenced by an "@" value ("-" for standard input).
+func valueFile(value string) (string, bool) {
+	if !strings.HasPrefix(value, FileValuePrefix) || strings.HasPrefix(value, FileValuePrefix+FileValuePrefix) ||
+		len(value) == len(FileValuePrefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(value, FileValuePrefix), true
+}
+
+func unescapeFileValue(value string) string {
+	if strings.HasPrefix(value, FileValuePrefix+FileValuePrefix) {
+		return strings.TrimPrefix(value, FileValuePrefix)
+	}
+
+	return value
+}
+
+func stdinOf(cmd *cobra.Command) io.Reader {
+	if cmd == nil {
+		return os.Stdin
+	}
+
+	return cmd.InOrStdin()
+}
@@ -21,23 +21,57 @@ func GetCertPool(useSystemCertPool bool, tlsCACerts []string) (*x509.CertPool, e
 	}
 
 	for _, v := range tlsCACerts {
-		bytes, errRead := os.ReadFile(path.Clean(v))
-		if errRead != nil {
-			return nil, fmt.Errorf("failed to read cert: %w", errRead)
+		cert, errParse := ParseCACertFile(v)
+		if errParse != nil {
+			return nil, errParse
 		}
 
-		block, _ := pem.Decode(bytes)
-		if block == nil {
-			return nil, fmt.Errorf("failed to decode pem")
+		certPool.Add(cert)
+	}
+
+	return certPool.Get()
+}
+
+// ParseCACertFile returns the CA certificate in the given PEM file, i.e. the certificate in its first
+// PEM block, as added to the pool by GetCertPool.
+func ParseCACertFile(certPath string) (*x509.Certificate, error) {
+	bytes, err := os.ReadFile(path.Clean(certPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cert: %w", err)
+	}
+
+	block, _ := pem.Decode(bytes)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode pem")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse cert: %w", err)
+	}
+
+	return cert, nil
+}
+
+// ParseCertificateFile returns the first certificate in the given PEM file.
+func ParseCertificateFile(certPath string) (*x509.Certificate, error) {
+	bytes, err := os.ReadFile(path.Clean(certPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cert: %w", err)
+	}
+
+	for block, rest := pem.Decode(bytes); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
 		}
 
-		cert, errParse := x509.ParseCertificate(block.Bytes)
-		if errParse != nil {
-			return nil, fmt.Errorf("failed to parse cert: %w", errParse)
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse cert: %w", err)
 		}
 
-		certPool.Add(cert)
+		return cert, nil
 	}
 
-	return certPool.Get()
+	return nil, fmt.Errorf("no certificate found in %s", certPath)
 }